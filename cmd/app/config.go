@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"server/config"
+)
+
+const configUsage = `usage: app config <command> [flags]
+
+commands:
+  print           show the effective configuration, with secrets redacted, and any invalid settings
+
+The flags are those of the server, such as -config=app.yaml or -server.port=9090.`
+
+// runConfig implements the "config" subcommand.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		return err
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
@@ -1,18 +1,33 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"server/config"
+	"server/db/migrations"
 	"server/internal/handlers"
+	"server/internal/models"
 	"server/internal/repositories"
 	"server/internal/services"
+	"server/pkg/cache"
+	"server/pkg/health"
 	"server/pkg/logger"
 	"server/pkg/middlewares"
+	"server/pkg/migrate"
+	"server/pkg/ratelimit"
+	"server/pkg/tracing"
 	pg "server/pkg/utils"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/gin-gonic/gin"
@@ -20,96 +35,334 @@ import (
 )
 
 func main() {
-	gin.SetMode(gin.ReleaseMode) // Set Gin to release mode for production
-	r := gin.Default()
+	// "app migrate ..." manages the schema instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// "app config print ..." shows the effective configuration instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	// Error handling for SetTrustedProxies method
-	if err := r.SetTrustedProxies(nil); err != nil {
-		log.Fatalf("Error setting trusted proxies: %v", err)
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
 	}
+}
+
+// run serves requests until SIGINT or SIGTERM, then shuts down gracefully: readiness starts
+// failing, in-flight requests drain, and background workers stop before connections are closed.
+func run(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	logger.InitLogger("development")
+	// Refuse to start with any invalid setting, listing them all
+	cfg, err := config.Load(args)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	logger.InitLogger(cfg.Server.Env)
 	defer logger.Sync()
 
-	// Load server port from config
-	port := config.LoadConfig().ServerPort
-	if port == "" {
-		port = "8080"
+	// Export traces of requests, service calls and queries; spans still buffered are flushed on exit
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+			ServiceName: cfg.Tracing.ServiceName,
+			Environment: cfg.Server.Env,
+			Exporter:    cfg.Tracing.Exporter,
+			Endpoint:    cfg.Tracing.Endpoint,
+			File:        cfg.Tracing.File,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set up tracing: %w", err)
+		}
+		defer func() {
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(flushCtx); err != nil {
+				logger.Error("Failed to flush traces", zap.Error(err))
+			}
+		}()
+	}
+
+	gin.SetMode(cfg.Server.GinMode)
+	// Requests are logged, and panics recovered, by our middlewares rather than gin's own
+	r := gin.New()
+
+	// Believe X-Forwarded-For only from the configured proxies; without any, the client IP is the peer address
+	var trustedProxies []string
+	if len(cfg.Server.TrustedProxies) > 0 {
+		trustedProxies = cfg.Server.TrustedProxies
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return fmt.Errorf("error setting trusted proxies: %w", err)
 	}
 
-	// Setup CORS config & middleware
-	r.Use(cors.New(cors.Config{
-		AllowAllOrigins: true, // Allow specific origins instead of all
-		// AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		// AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		// ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	// IDs of new rows are generated by this node
+	node, err := newSnowflakeNode(cfg.Snowflake)
+	if err != nil {
+		return fmt.Errorf("invalid snowflake configuration: %w", err)
+	}
+	pg.SetDefaultSnowflakeNode(node)
+
+	// Trace every request, continuing the caller's trace when it sends a traceparent header
+	if cfg.Tracing.Enabled {
+		r.Use(tracing.Middleware(cfg.Tracing.ServiceName, "/healthz", "/readyz", "/metrics"))
+	}
+
+	// Tag every request with an ID for log and audit correlation, and log it once answered; first,
+	// so requests rejected by the middlewares below are logged too. Panics below are logged with the
+	// request ID and answered with a 500 that the access log records.
+	r.Use(middlewares.RequestID())
+	r.Use(middlewares.Logging("/healthz", "/readyz", "/metrics"))
+	r.Use(middlewares.Recovery())
+
+	// Answer preflights and allow only the configured origins
+	r.Use(middlewares.CORS(middlewares.CORSPolicy{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
 	}))
 
-	// Add XSS Middleware for sanitizing input data
-	// r.Use(middlewares.XSSMiddleware())
+	// Send CSP, HSTS, frame, sniffing and referrer headers; violations of the CSP are logged by /csp-report
+	if cfg.Security.Enabled {
+		r.Use(middlewares.SecurityHeaders(middlewares.SecurityPolicy{
+			ContentSecurityPolicy: cfg.Security.ContentSecurityPolicy,
+			CSPReportOnly:         cfg.Security.CSPReportOnly,
+			HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+			HSTSIncludeSubdomains: cfg.Security.HSTSIncludeSubdomains,
+			FrameDeny:             cfg.Security.FrameDeny,
+			ReferrerPolicy:        cfg.Security.ReferrerPolicy,
+		}))
+	}
+
+	// Sanitize HTML in request bodies
+	if cfg.Features.XSSSanitization {
+		r.Use(middlewares.XSSMiddleware())
+	}
+
+	// Bound every request, and the queries it runs, by its route's timeout
+	r.Use(middlewares.Timeout(cfg.Server.RequestTimeout, cfg.Server.RouteTimeouts))
 
 	// Add the monitoring middleware for Prometheus metrics
 	r.Use(middlewares.MonitoringMiddleware())
-	r.Use(middlewares.Logging())
-
-	// Add secure headers middleware for enhanced security
-	// secureConfig := secure.New(secure.Config{
-	// 	SSLRedirect:           true,                                                        // Force HTTPS
-	// 	FrameDeny:             true,                                                        // Deny iframe embedding (Clickjacking)
-	// 	ContentTypeNosniff:    true,                                                        // Prevent MIME type sniffing
-	// 	BrowserXssFilter:      true,                                                        // Enable browser's XSS filter
-	// 	ContentSecurityPolicy: "default-src 'self'; script-src 'self'; object-src 'none';", // Apply Content Security Policy (CSP)
-	// })
-	// r.Use(secureConfig)
+
+	// Tag GET responses with ETags and caching headers, and answer conditional GETs with 304
+	if cfg.HTTPCache.Enabled {
+		r.Use(middlewares.HTTPCache(cfg.HTTPCache.MaxAge, cfg.HTTPCache.RouteMaxAge))
+	}
+
+	// Render errors reported by handlers as problem+json; must be the last global middleware
+	r.Use(middlewares.ErrorHandler())
 
 	// Database connection
-	db, err := pg.NewDB()
+	db, err := pg.NewDB(cfg.Database)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer db.Close()
 
-	// Expose Prometheus metrics at "/metrics"
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// Export the connection pool stats as go_sql_* metrics
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, cfg.Database.Name))
 
-	// Register routes
-	r.GET("/ping", func(c *gin.Context) {
-		// Create a FacilityRepository instance using the existing db connection
-		fRepo := repositories.NewFacilityRepository(db)
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
 
-		// Fetch a facility from the repository
-		f, err := fRepo.Find(1)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Facility not found"})
-			return
+	// Apply pending migrations; the advisory lock lets replicas start concurrently
+	if cfg.Database.AutoMigrate {
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
 		}
+	}
 
-		c.JSON(200, gin.H{
-			"message":  "pong",
-			"facility": f,
-		})
-	})
+	// Redis is optional; without REDIS_HOST it is neither used nor checked
+	redisClient := pg.NewRedisClient(cfg.Redis)
+	if redisClient != nil {
+		defer redisClient.Close()
+	}
+
+	// Readiness fails while a dependency is unreachable or the server is shutting down
+	checker := health.NewChecker(cfg.Server.HealthCheckTimeout)
+	checker.Add("database", health.Ping(db.PingContext))
+	checker.Add("migrations", health.MigrationVersion(migrator))
+	if redisClient != nil {
+		checker.Add("redis", health.Ping(func(ctx context.Context) error { return redisClient.Ping(ctx).Err() }))
+	}
+	handlers.NewHealthHandler(checker).RegisterHealthRoutes(r)
+
+	// Expose Prometheus metrics at "/metrics"
+	if cfg.Features.Metrics {
+		r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
+
+	// Background workers run until the server has drained
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
 
 	// Initialize repositories
 	cityRepo := repositories.NewCitiesRepository(db)
 	facilityRepo := repositories.NewFacilityRepository(db)
+
+	// Cache facility and city lookups; every instance drops the entries another one invalidates
+	if cfg.Cache.Enabled {
+		cacheOptions := cache.Options{LocalSize: cfg.Cache.LocalSize, LocalTTL: cfg.Cache.LocalTTL, TTL: cfg.Cache.TTL, LoadTimeout: cfg.Cache.LoadTimeout}
+		facilityCache := cache.New[models.Facility]("facilities", redisClient, cacheOptions)
+		cityCache := cache.New[models.City]("cities", redisClient, cacheOptions)
+		facilityRepo = repositories.NewCachedFacilityRepository(facilityRepo, facilityCache)
+		cityRepo = repositories.NewCachedCitiesRepository(cityRepo, cityCache)
+
+		workers.Add(2)
+		go func() {
+			defer workers.Done()
+			facilityCache.Run(workersCtx)
+		}()
+		go func() {
+			defer workers.Done()
+			cityCache.Run(workersCtx)
+		}()
+	}
 	authRepo := repositories.NewAuthRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	transactor := repositories.NewTransactor(db)
+	trashService := services.NewTrashService(db, transactor, cfg.Trash.Retention)
+	facilityMetricsService := services.NewFacilityMetricsService(
+		repositories.NewFacilityMetricsRepository(db), facilityRepo, cfg.FacilityMetrics.Retention)
 
 	// Initialize services
+	authService := services.NewAuthService(authRepo, []byte(cfg.Auth.JWTSecret), cfg.Auth.TokenTTL)
 	serviceGroup := &handlers.Services{
-		CityService:     services.NewCityService(cityRepo),
-		FacilityService: services.NewFacilityService(facilityRepo),
-		AuthService:     services.NewAuthService(authRepo),
+		CityService:            services.NewCityService(cityRepo),
+		FacilityService:        services.NewFacilityService(facilityRepo, transactor),
+		AuthService:            authService,
+		AuditService:           services.NewAuditService(auditLogRepo, transactor),
+		TrashService:           trashService,
+		FacilityMetricsService: facilityMetricsService,
+	}
+
+	// Limit the request rate of each API client; Redis shares the limits between replicas
+	var apiMiddlewares []gin.HandlerFunc
+	if cfg.RateLimit.Enabled {
+		var store ratelimit.Store = ratelimit.NewMemoryStore()
+		if redisClient != nil {
+			store = ratelimit.NewRedisStore(redisClient, store)
+		}
+		apiMiddlewares = append(apiMiddlewares, middlewares.RateLimit(store, rateLimitPolicy(cfg.RateLimit, authService)))
+	}
+
+	// Register handlers; the CSP report collector takes unauthenticated bodies and is rate limited too
+	handlers.RegisterHandlers(r, serviceGroup, apiMiddlewares...)
+	handlers.RegisterCSPReportRoutes(r, apiMiddlewares...)
+
+	// Hard-delete soft-deleted records once their retention period has passed
+	if cfg.Trash.PurgeInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			trashService.RunPurgeJob(workersCtx, cfg.Trash.PurgeInterval)
+		}()
 	}
 
-	// Register handlers
-	handlers.RegisterHandlers(r, serviceGroup)
+	// Create the facility metrics partitions of the coming days and drop those past retention
+	if cfg.FacilityMetrics.MaintenanceInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			facilityMetricsService.RunMaintenanceJob(workersCtx, cfg.FacilityMetrics.MaintenanceInterval)
+		}()
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	logger.Info("Application started", zap.String("env", cfg.Server.Env), zap.String("addr", srv.Addr))
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+	// A second signal terminates the process without waiting
+	stop()
+
+	logger.Info("Shutting down", zap.Duration("delay", cfg.Server.ShutdownDelay), zap.Duration("timeout", cfg.Server.ShutdownTimeout))
+	checker.ShutDown()
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Requests did not drain before the shutdown timeout", zap.Error(err))
+	}
 
-	logger.Info("Application started", zap.String("env", "development"))
+	stopWorkers()
+	workers.Wait()
 
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	logger.Info("Shutdown complete")
+	return nil
+}
+
+// rateLimitPolicy translates the rate limit settings, identifying users by their verified tokens.
+func rateLimitPolicy(cfg config.RateLimitConfig, authService *services.AuthService) middlewares.RateLimitPolicy {
+	routes := make(map[string]ratelimit.Limit, len(cfg.Routes))
+	for route, rate := range cfg.Routes {
+		routes[route] = ratelimit.Limit{Rate: rate.PerSecond(), Burst: rate.Requests}
+	}
+	return middlewares.RateLimitPolicy{
+		Default: ratelimit.Limit{Rate: cfg.RequestsPerSecond, Burst: cfg.Burst},
+		Routes:  routes,
+		UserOf: func(c *gin.Context) string {
+			token := c.GetHeader("Authorization")
+			if token == "" {
+				return ""
+			}
+			claims, err := authService.ValidateAuthToken(token)
+			if err != nil {
+				return ""
+			}
+			return claims.UserID
+		},
+		APIKeys: cfg.APIKeys,
+	}
+}
+
+// newSnowflakeNode builds the ID generator of this process. Without SNOWFLAKE_MACHINE_ID the machine
+// ID is the pod ordinal, so StatefulSet replicas never share one; elsewhere it falls back to 0.
+func newSnowflakeNode(cfg config.SnowflakeConfig) (*pg.SnowflakeNode, error) {
+	machineID := cfg.MachineID
+	if machineID < 0 {
+		hostname, _ := os.Hostname()
+		derived, err := pg.MachineIDFromHostname(hostname)
+		if errors.Is(err, pg.ErrInvalidNodeID) {
+			return nil, err
+		}
+		if err != nil {
+			logger.Info("Using snowflake machine ID 0", zap.String("reason", err.Error()))
+		}
+		machineID = derived
 	}
+	return pg.NewSnowflakeNode(cfg.DataCenterID, machineID, nil)
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"server/config"
+	"server/db/migrations"
+	"server/internal/models"
+	"server/internal/schemacheck"
+	"server/pkg/migrate"
+	pg "server/pkg/utils"
+)
+
+const migrateUsage = `usage: app migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [steps]    roll back the last <steps> migrations (default 1)
+  status          list migrations and when they were applied
+  to <version>    migrate up or down to exactly <version> (0 rolls back everything)
+  check           report drift between the migrated schema and the Go models`
+
+// runMigrate implements the "migrate" subcommand.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg, err := config.Load(nil)
+	if err == nil {
+		err = cfg.Database.Validate()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	db, err := pg.NewDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrator.To(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	case "check":
+		drifts, err := schemacheck.Check(ctx, db, models.AllTables()...)
+		if err != nil {
+			return err
+		}
+		for _, drift := range drifts {
+			fmt.Println(drift)
+		}
+		if len(drifts) > 0 {
+			return fmt.Errorf("schema drift detected: %d difference(s)", len(drifts))
+		}
+		fmt.Println("schema matches models")
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
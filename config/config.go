@@ -1,65 +1,362 @@
+// Package config holds the server's settings. They are layered from defaults, an optional YAML
+// file, environment variables and command-line flags, each overriding the one before; see Load.
 package config
 
 import (
 	"fmt"
-	"os"
 	"strconv"
-
-	"github.com/joho/godotenv"
+	"strings"
+	"time"
 )
 
+// Config is the complete configuration of the server.
 type Config struct {
-	ServerPort string
+	Server          ServerConfig          `yaml:"server"`
+	Database        DatabaseConfig        `yaml:"database"`
+	Redis           RedisConfig           `yaml:"redis"`
+	Cache           CacheConfig           `yaml:"cache"`
+	Auth            AuthConfig            `yaml:"auth"`
+	CORS            CORSConfig            `yaml:"cors"`
+	Security        SecurityConfig        `yaml:"security"`
+	HTTPCache       HTTPCacheConfig       `yaml:"http_cache"`
+	RateLimit       RateLimitConfig       `yaml:"rate_limit"`
+	Notifications   NotificationsConfig   `yaml:"notifications"`
+	Features        FeatureFlags          `yaml:"features"`
+	Trash           TrashConfig           `yaml:"trash"`
+	FacilityMetrics FacilityMetricsConfig `yaml:"facility_metrics"`
+	Snowflake       SnowflakeConfig       `yaml:"snowflake"`
+	Tracing         TracingConfig         `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port" env:"SERVER_PORT"`
+	// TrustedProxies lists the addresses or CIDRs of the proxies whose X-Forwarded-For headers are
+	// believed when determining the client IP, which rate limits are keyed by.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	// Env selects environment-specific behavior such as the log format: development or production.
+	Env     string `yaml:"env" env:"APP_ENV"`
+	GinMode string `yaml:"gin_mode" env:"GIN_MODE"`
+	// RequestTimeout bounds the work done for a request, including its database queries.
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT"`
+	// RouteTimeouts overrides RequestTimeout per route, keyed by "METHOD /path" as registered with gin.
+	RouteTimeouts map[string]time.Duration `yaml:"route_timeouts" env:"ROUTE_TIMEOUTS"`
+	// ShutdownDelay is how long the server keeps serving after a termination signal while readiness
+	// fails, so load balancers stop sending requests before connections are closed.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+	// ShutdownTimeout bounds how long in-flight requests get to finish once shutdown starts.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// HealthCheckTimeout bounds each dependency check of the readiness probe.
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
-	Driver   string
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSLMODE"`
+	Driver   string `yaml:"driver" env:"DB_DRIVER"`
+	// AutoMigrate applies pending schema migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	// Connection pool limits; zero lifetimes keep connections open indefinitely.
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+}
+
+// RedisConfig locates the Redis server. An empty Host means Redis is not used.
+type RedisConfig struct {
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     int    `yaml:"port" env:"REDIS_PORT"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
+
+// CacheConfig sizes the read-through cache of facility and city lookups. It is shared through Redis
+// when Redis is configured and kept in process otherwise.
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled" env:"CACHE_ENABLED"`
+	// LocalSize is the number of entries each cache keeps in process. LocalTTL bounds how long an
+	// instance that missed an invalidation, for example while Redis was unreachable, serves stale data.
+	LocalSize int           `yaml:"local_size" env:"CACHE_LOCAL_SIZE"`
+	LocalTTL  time.Duration `yaml:"local_ttl" env:"CACHE_LOCAL_TTL"`
+	// TTL is how long entries live in Redis.
+	TTL time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	// LoadTimeout bounds a database load shared by concurrent misses of the same entry.
+	LoadTimeout time.Duration `yaml:"load_timeout" env:"CACHE_LOAD_TIMEOUT"`
+}
+
+type AuthConfig struct {
+	// JWTSecret signs and verifies access tokens.
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	TokenTTL  time.Duration `yaml:"token_ttl" env:"JWT_TOKEN_TTL"`
+}
+
+// CORSConfig decides which web origins may call the API from a browser.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the API; "*" allows any, outside production
+	// and without credentials only.
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods []string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	// ExposedHeaders are the response headers scripts of other origins may read.
+	ExposedHeaders []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	// AllowCredentials lets browsers send cookies with cross-origin requests. Bearer tokens in the
+	// Authorization header do not need it.
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+// SecurityConfig sets the headers that tell browsers how to protect the users of responses.
+type SecurityConfig struct {
+	Enabled bool `yaml:"enabled" env:"SECURITY_HEADERS_ENABLED"`
+	// ContentSecurityPolicy is sent in Content-Security-Policy, or only reported on with
+	// CSPReportOnly. Violations are reported to /csp-report.
+	ContentSecurityPolicy string `yaml:"content_security_policy" env:"SECURITY_CSP"`
+	CSPReportOnly         bool   `yaml:"csp_report_only" env:"SECURITY_CSP_REPORT_ONLY"`
+	// HSTSMaxAge is how long browsers must only use HTTPS; zero sends no Strict-Transport-Security.
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+	// FrameDeny forbids rendering responses in frames, which prevents clickjacking.
+	FrameDeny      bool   `yaml:"frame_deny" env:"SECURITY_FRAME_DENY"`
+	ReferrerPolicy string `yaml:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
 }
 
-type LoadedConfig struct {
-	Config
-	DatabaseConfig
+// HTTPCacheConfig sets the Cache-Control max-age of successful GET responses. Every GET response
+// gets an ETag and can be revalidated, whatever its max-age.
+type HTTPCacheConfig struct {
+	Enabled bool `yaml:"enabled" env:"HTTP_CACHE_ENABLED"`
+	// MaxAge is how long clients may reuse a response without revalidating it; zero makes them
+	// revalidate every time.
+	MaxAge time.Duration `yaml:"max_age" env:"HTTP_CACHE_MAX_AGE"`
+	// RouteMaxAge overrides MaxAge per route, keyed by "GET /path" as registered with gin.
+	RouteMaxAge map[string]time.Duration `yaml:"route_max_age" env:"HTTP_CACHE_ROUTE_MAX_AGE"`
 }
 
-func LoadConfig() LoadedConfig {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found. Using default environment variables.")
+// RateLimitConfig limits the request rate of each client of the API: the authenticated user, the
+// holder of an API key, or else the client IP.
+type RateLimitConfig struct {
+	// Enabled is on by default, limiting each instance on its own unless Redis is configured. It
+	// cannot be turned off in production.
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// RequestsPerSecond is the sustained rate allowed per client, Burst the requests allowed at once.
+	// Together they limit every route without a limit in Routes.
+	RequestsPerSecond float64 `yaml:"requests_per_second" env:"RATE_LIMIT_RPS"`
+	Burst             int     `yaml:"burst" env:"RATE_LIMIT_BURST"`
+	// Routes gives routes a limit of their own, keyed by "METHOD /path" as registered with gin.
+	Routes map[string]Rate `yaml:"routes" env:"RATE_LIMIT_ROUTES"`
+	// APIKeys are the X-API-Key values whose holders are limited on their own rather than by IP.
+	APIKeys []string `yaml:"api_keys" env:"RATE_LIMIT_API_KEYS" secret:"true"`
+}
+
+// Rate is a number of requests per period, written like "5/1m". A client may make them all at once
+// and gets them back evenly over the period. "0" is unlimited.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// PerSecond is the rate at which requests are given back.
+func (r Rate) PerSecond() float64 {
+	if r.Period <= 0 {
+		return 0
 	}
+	return float64(r.Requests) / r.Period.Seconds()
+}
 
-	return LoadedConfig{
-		Config: Config{
-			ServerPort: getEnv("SERVER_PORT", "8080"),
-		},
-		DatabaseConfig: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 3002),
-			User:     getEnv("DB_USER", "user"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			DBName:   getEnv("DB_NAME", "mydb"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-			Driver:   getEnv("DB_DRIVER", "postgres"),
-		},
+func (r Rate) MarshalText() ([]byte, error) {
+	if r.Requests == 0 {
+		return []byte("0"), nil
+	}
+	period := r.Period.String()
+	// "1m0s" reads better as "1m", and "1h0m0s" as "1h"
+	for _, zero := range []string{"m0s", "h0m"} {
+		if strings.HasSuffix(period, zero) {
+			period = period[:len(period)-2]
+		}
 	}
+	return []byte(strconv.Itoa(r.Requests) + "/" + period), nil
 }
 
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func (r *Rate) UnmarshalText(text []byte) error {
+	raw := strings.TrimSpace(string(text))
+	if raw == "0" {
+		*r = Rate{}
+		return nil
+	}
+	requests, period, found := strings.Cut(raw, "/")
+	n, err := strconv.Atoi(requests)
+	if !found || err != nil || n <= 0 {
+		return fmt.Errorf("invalid rate %q, want requests/period such as 5/1m", raw)
 	}
-	return fallback
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid rate %q, want requests/period such as 5/1m", raw)
+	}
+	*r = Rate{Requests: n, Period: d}
+	return nil
+}
+
+// NotificationsConfig configures the channels notifications are delivered through.
+type NotificationsConfig struct {
+	Email EmailConfig `yaml:"email"`
+	SMS   SMSConfig   `yaml:"sms"`
 }
 
-func getEnvAsInt(key string, fallback int) int {
-	valueStr := getEnv(key, "")
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+type EmailConfig struct {
+	Enabled  bool   `yaml:"enabled" env:"NOTIFY_EMAIL_ENABLED"`
+	SMTPHost string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"NOTIFY_EMAIL_FROM"`
+}
+
+type SMSConfig struct {
+	Enabled  bool   `yaml:"enabled" env:"NOTIFY_SMS_ENABLED"`
+	Provider string `yaml:"provider" env:"SMS_PROVIDER"`
+	APIKey   string `yaml:"api_key" env:"SMS_API_KEY" secret:"true"`
+	Sender   string `yaml:"sender" env:"SMS_SENDER"`
+}
+
+// FeatureFlags switch optional behavior on or off.
+type FeatureFlags struct {
+	// Metrics exposes Prometheus metrics at /metrics.
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS"`
+	// XSSSanitization sanitizes HTML in query parameters and request bodies. It is on by default.
+	XSSSanitization bool `yaml:"xss_sanitization" env:"FEATURE_XSS_SANITIZATION"`
+}
+
+type TrashConfig struct {
+	// Retention is how long soft-deleted records stay restorable before they are purged.
+	Retention time.Duration `yaml:"retention" env:"TRASH_RETENTION"`
+	// PurgeInterval is how often the purge job runs; zero disables it.
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL"`
+}
+
+type FacilityMetricsConfig struct {
+	// Retention is how long facility metric samples are kept. Older samples are rejected on
+	// ingestion and their daily partitions dropped.
+	Retention time.Duration `yaml:"retention" env:"FACILITY_METRICS_RETENTION"`
+	// MaintenanceInterval is how often partitions are created ahead and dropped past retention;
+	// zero disables it.
+	MaintenanceInterval time.Duration `yaml:"maintenance_interval" env:"FACILITY_METRICS_MAINTENANCE_INTERVAL"`
+}
+
+// SnowflakeConfig identifies this process in generated IDs. A negative machine ID derives it from
+// the ordinal of the StatefulSet pod's hostname.
+type SnowflakeConfig struct {
+	DataCenterID int64 `yaml:"datacenter_id" env:"SNOWFLAKE_DATACENTER_ID"`
+	MachineID    int64 `yaml:"machine_id" env:"SNOWFLAKE_MACHINE_ID"`
+}
+
+// TracingConfig exports OpenTelemetry traces of requests, service calls and queries.
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled" env:"TRACING_ENABLED"`
+	// Exporter is otlp, which sends spans to Endpoint over OTLP/HTTP, or stdout or file, which
+	// write them as JSON for local development.
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	Endpoint string `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	File     string `yaml:"file" env:"TRACING_FILE"`
+	// SampleRatio is the share of traces started by this server that are recorded, from 0 to 1.
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// Default returns the configuration used for every setting no source overrides.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:               "8080",
+			Env:                "development",
+			GinMode:            "release",
+			RequestTimeout:     10 * time.Second,
+			RouteTimeouts:      map[string]time.Duration{"POST /api/admin/facilities/:id/restore": 60 * time.Second},
+			ShutdownDelay:      5 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:         "localhost",
+			Port:         3002,
+			User:         "user",
+			Password:     "password",
+			Name:         "mydb",
+			SSLMode:      "disable",
+			Driver:       "postgres",
+			AutoMigrate:  true,
+			MaxOpenConns: 25,
+			MaxIdleConns: 25,
+		},
+		Redis: RedisConfig{Port: 6379},
+		Cache: CacheConfig{
+			Enabled:     true,
+			LocalSize:   10000,
+			LocalTTL:    30 * time.Second,
+			TTL:         5 * time.Minute,
+			LoadTimeout: 10 * time.Second,
+		},
+		Auth: AuthConfig{TokenTTL: 24 * time.Hour},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{
+				"Origin", "Content-Type", "Accept", "Accept-Language", "Authorization",
+				"If-Match", "If-None-Match", "If-Modified-Since", "X-API-Key", "X-Request-ID",
+			},
+			ExposedHeaders: []string{
+				"ETag", "Last-Modified", "Retry-After", "X-Request-ID",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+			},
+			MaxAge: 12 * time.Hour,
+		},
+		Security: SecurityConfig{
+			Enabled: true,
+			// The API serves JSON only, so nothing a response could load or embed is allowed
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			FrameDeny:             true,
+			ReferrerPolicy:        "no-referrer",
+		},
+		HTTPCache: HTTPCacheConfig{
+			Enabled: true,
+			RouteMaxAge: map[string]time.Duration{
+				"GET /api/facilities": time.Minute,
+				"GET /api/cities/:id": time.Hour,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           true,
+			RequestsPerSecond: 10,
+			Burst:             20,
+			Routes: map[string]Rate{
+				"POST /api/login":            {Requests: 5, Period: time.Minute},
+				"POST /api/register":         {Requests: 3, Period: time.Minute},
+				"GET /api/facilities/search": {Requests: 30, Period: time.Second},
+			},
+		},
+		Notifications: NotificationsConfig{
+			Email: EmailConfig{SMTPPort: 587},
+		},
+		Features: FeatureFlags{Metrics: true, XSSSanitization: true},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		FacilityMetrics: FacilityMetricsConfig{
+			Retention:           90 * 24 * time.Hour,
+			MaintenanceInterval: time.Hour,
+		},
+		Snowflake: SnowflakeConfig{MachineID: -1},
+		Tracing: TracingConfig{
+			Exporter:    "otlp",
+			Endpoint:    "http://localhost:4318",
+			File:        "traces.json",
+			SampleRatio: 1,
+			ServiceName: "gin_app",
+		},
 	}
-	return fallback
 }
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable holding the path of the YAML config file. The -config
+// flag takes precedence over it.
+const FileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from, in increasing precedence: Default, the YAML file, the
+// environment (including a .env file in the working directory) and args. Every setting has a flag
+// named after its YAML path, such as -server.port=9090.
+//
+// Load reports every malformed value at once. It does not validate the result; call Validate.
+func Load(args []string) (Config, error) {
+	// Variables already set in the environment win over .env
+	_ = godotenv.Load()
+
+	cfg := Default()
+	settings := settingsOf(&cfg)
+
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(FileEnv), "path of the YAML config file")
+	type flagValue struct {
+		setting setting
+		raw     string
+	}
+	var flagValues []flagValue
+	for _, s := range settings {
+		s := s
+		flags.Func(s.path, "(env "+s.env+")", func(raw string) error {
+			flagValues = append(flagValues, flagValue{s, raw})
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(&cfg, *configFile); err != nil {
+			return cfg, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if raw, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for _, fv := range flagValues {
+		if err := fv.setting.set(fv.raw); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", fv.setting.path, err))
+		}
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// loadFile overlays the settings present in a YAML file. Unknown keys are rejected so typos do not
+// go unnoticed.
+func loadFile(cfg *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// setting is a single configuration value together with the names it is set by.
+type setting struct {
+	path   string // YAML path, e.g. "server.port"; also the flag name
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// settingsOf lists the settings of cfg in declaration order. Their values point into cfg.
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			path := prefix + field.Tag.Get("yaml")
+			if field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			settings = append(settings, setting{
+				path:   path,
+				env:    field.Tag.Get("env"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return settings
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// set parses raw into the setting. Lists are comma-separated, and maps are comma-separated
+// key=value pairs. Types implementing encoding.TextUnmarshaler parse themselves.
+func (s setting) set(raw string) error {
+	v := s.value
+	switch {
+	case v.Addr().Type().Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		v.Set(reflect.ValueOf(splitList(raw)))
+	case v.Kind() == reflect.Map && v.Type().Elem() == durationType:
+		durations := map[string]time.Duration{}
+		for _, pair := range splitList(raw) {
+			key, value, found := strings.Cut(pair, "=")
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if !found || err != nil {
+				return fmt.Errorf("invalid entry %q, want key=duration", pair)
+			}
+			durations[strings.TrimSpace(key)] = d
+		}
+		v.Set(reflect.ValueOf(durations))
+	case v.Kind() == reflect.Map && reflect.PointerTo(v.Type().Elem()).Implements(textUnmarshalerType):
+		values := reflect.MakeMap(v.Type())
+		for _, pair := range splitList(raw) {
+			key, value, found := strings.Cut(pair, "=")
+			if !found {
+				return fmt.Errorf("invalid entry %q, want key=value", pair)
+			}
+			elem := reflect.New(v.Type().Elem())
+			if err := elem.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+				return err
+			}
+			values.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), elem.Elem())
+		}
+		v.Set(values)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+func splitList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
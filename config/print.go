@@ -0,0 +1,90 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces the value of secret settings that are set.
+const Redacted = "[REDACTED]"
+
+// Print writes the configuration to w in the format of the YAML config file, with secrets redacted.
+// Each setting is annotated with the environment variable that overrides it.
+func (c Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{"": root}
+
+	for _, s := range settingsOf(&c) {
+		parent, key := sectionOf(sections, s.path)
+
+		value := &yaml.Node{}
+		if err := value.Encode(printable(s)); err != nil {
+			return err
+		}
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		if len(value.Content) == 0 {
+			// Empty lists and maps print inline, as [] or {}, with the comment after them
+			value.Style = yaml.FlowStyle
+		}
+		if value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+			value.LineComment = "env " + s.env
+		} else {
+			keyNode.LineComment = "env " + s.env
+		}
+		parent.Content = append(parent.Content, keyNode, value)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// sectionOf returns the mapping node holding the setting at path, creating the mappings of its
+// sections on first use, and the setting's key within it.
+func sectionOf(sections map[string]*yaml.Node, path string) (*yaml.Node, string) {
+	dot := strings.LastIndex(path, ".")
+	if dot < 0 {
+		return sections[""], path
+	}
+	section, key := path[:dot], path[dot+1:]
+	if node, ok := sections[section]; ok {
+		return node, key
+	}
+	parent, name := sectionOf(sections, section)
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, node)
+	sections[section] = node
+	return node, key
+}
+
+// printable converts a setting to the form it is written in: durations as strings like "30s"
+// rather than nanoseconds, and secrets redacted.
+func printable(s setting) any {
+	v := s.value
+	switch {
+	case s.secret && v.Len() > 0:
+		return Redacted
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Map && v.Type().Elem() == durationType:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		durations := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range keys {
+			durations.Content = append(durations.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key.String()},
+				&yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(v.MapIndex(key).Int()).String()})
+		}
+		return durations
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minJWTSecretLength is the shortest accepted JWT secret: 256 bits, the size of an HS256 key.
+const minJWTSecretLength = 32
+
+// problems collects the invalid settings of a configuration, so they can all be reported at once.
+type problems []error
+
+func (p *problems) check(ok bool, path, format string, args ...any) {
+	if !ok {
+		*p = append(*p, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (p *problems) oneOf(value, path string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	p.check(false, path, "must be one of %v, got %q", allowed, value)
+}
+
+func (p *problems) port(value int, path string) {
+	p.check(value > 0 && value <= 65535, path, "must be a port between 1 and 65535, got %d", value)
+}
+
+// Validate reports every invalid setting, each prefixed with its YAML path.
+func (c Config) Validate() error {
+	var p problems
+	c.Server.validate(&p)
+	c.Database.validate(&p)
+	c.Redis.validate(&p)
+	c.Cache.validate(&p)
+	c.Auth.validate(&p)
+	c.CORS.validate(&p, c.Server.Env)
+	c.Security.validate(&p)
+	c.HTTPCache.validate(&p)
+	c.RateLimit.validate(&p, c.Server.Env)
+	c.Notifications.validate(&p)
+	c.Trash.validate(&p)
+	c.FacilityMetrics.validate(&p)
+	c.Tracing.validate(&p)
+	return errors.Join(p...)
+}
+
+// Validate reports every invalid database setting. Commands that only need the database, such as
+// migrate, validate just this section.
+func (c DatabaseConfig) Validate() error {
+	var p problems
+	c.validate(&p)
+	return errors.Join(p...)
+}
+
+func (c ServerConfig) validate(p *problems) {
+	port, err := strconv.Atoi(c.Port)
+	p.check(err == nil && port > 0 && port <= 65535, "server.port", "must be a port between 1 and 65535, got %q", c.Port)
+	for _, proxy := range c.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		p.check(net.ParseIP(proxy) != nil || cidrErr == nil, "server.trusted_proxies", "%q is not an IP address or CIDR", proxy)
+	}
+	p.oneOf(c.Env, "server.env", "development", "production")
+	p.oneOf(c.GinMode, "server.gin_mode", "debug", "release", "test")
+	p.check(c.RequestTimeout >= 0, "server.request_timeout", "must not be negative")
+	for route, timeout := range c.RouteTimeouts {
+		p.check(timeout > 0, "server.route_timeouts", "timeout of %q must be positive", route)
+	}
+	p.check(c.ShutdownDelay >= 0, "server.shutdown_delay", "must not be negative")
+	p.check(c.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	p.check(c.HealthCheckTimeout > 0, "server.health_check_timeout", "must be positive")
+}
+
+func (c DatabaseConfig) validate(p *problems) {
+	p.check(c.Host != "", "database.host", "is required")
+	p.port(c.Port, "database.port")
+	p.check(c.Name != "", "database.name", "is required")
+	p.oneOf(c.Driver, "database.driver", "postgres")
+	p.oneOf(c.SSLMode, "database.ssl_mode", "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	p.check(c.MaxOpenConns > 0, "database.max_open_conns", "must be positive")
+	p.check(c.MaxIdleConns >= 0 && c.MaxIdleConns <= c.MaxOpenConns, "database.max_idle_conns",
+		"must be between 0 and max_open_conns (%d), got %d", c.MaxOpenConns, c.MaxIdleConns)
+	p.check(c.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	p.check(c.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+}
+
+func (c RedisConfig) validate(p *problems) {
+	if c.Host == "" {
+		return
+	}
+	p.port(c.Port, "redis.port")
+	p.check(c.DB >= 0, "redis.db", "must not be negative")
+}
+
+func (c CacheConfig) validate(p *problems) {
+	if !c.Enabled {
+		return
+	}
+	p.check(c.LocalSize > 0, "cache.local_size", "must be positive")
+	p.check(c.LocalTTL > 0, "cache.local_ttl", "must be positive")
+	p.check(c.TTL > 0, "cache.ttl", "must be positive")
+	p.check(c.LoadTimeout > 0, "cache.load_timeout", "must be positive")
+}
+
+func (c AuthConfig) validate(p *problems) {
+	p.check(len(c.JWTSecret) >= minJWTSecretLength, "auth.jwt_secret",
+		"must be at least %d characters", minJWTSecretLength)
+	p.check(c.TokenTTL > 0, "auth.token_ttl", "must be positive")
+}
+
+func (c CORSConfig) validate(p *problems, env string) {
+	p.check(len(c.AllowedOrigins) > 0, "cors.allowed_origins", "is required; use \"*\" to allow any origin")
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			p.check(len(c.AllowedOrigins) == 1, "cors.allowed_origins", "\"*\" cannot be combined with other origins")
+			p.check(!c.AllowCredentials, "cors.allowed_origins", "\"*\" cannot be combined with allow_credentials")
+			p.check(env != "production", "cors.allowed_origins", "\"*\" is not allowed in production; list the origins")
+			continue
+		}
+		u, err := url.Parse(origin)
+		p.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/"),
+			"cors.allowed_origins", "%q is not an origin such as https://example.com", origin)
+	}
+	p.check(len(c.AllowedMethods) > 0, "cors.allowed_methods", "is required")
+	for _, method := range c.AllowedMethods {
+		p.check(method != "" && method == strings.ToUpper(method) && !strings.ContainsAny(method, " ,"),
+			"cors.allowed_methods", "%q is not an HTTP method such as GET", method)
+	}
+	p.check(c.MaxAge >= 0, "cors.max_age", "must not be negative")
+}
+
+func (c SecurityConfig) validate(p *problems) {
+	if !c.Enabled {
+		return
+	}
+	p.check(!strings.Contains(c.ContentSecurityPolicy, "report-uri"), "security.content_security_policy",
+		"must not set report-uri; violations are reported to /csp-report")
+	p.check(!c.CSPReportOnly || c.ContentSecurityPolicy != "", "security.csp_report_only",
+		"needs a content_security_policy to report on")
+	p.check(c.HSTSMaxAge >= 0, "security.hsts_max_age", "must not be negative")
+	if c.ReferrerPolicy != "" {
+		p.oneOf(c.ReferrerPolicy, "security.referrer_policy", "no-referrer", "no-referrer-when-downgrade", "origin",
+			"origin-when-cross-origin", "same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url")
+	}
+}
+
+func (c HTTPCacheConfig) validate(p *problems) {
+	p.check(c.MaxAge >= 0, "http_cache.max_age", "must not be negative")
+	for route, maxAge := range c.RouteMaxAge {
+		p.check(strings.HasPrefix(route, "GET /"), "http_cache.route_max_age", "%q is not a GET route such as \"GET /api/cities/:id\"", route)
+		p.check(maxAge >= 0, "http_cache.route_max_age", "max-age of %q must not be negative", route)
+	}
+}
+
+func (c RateLimitConfig) validate(p *problems, env string) {
+	if !c.Enabled {
+		p.check(env != "production", "rate_limit.enabled", "cannot be disabled in production")
+		return
+	}
+	p.check(c.RequestsPerSecond > 0, "rate_limit.requests_per_second", "must be positive")
+	p.check(c.Burst >= 1, "rate_limit.burst", "must be at least 1")
+	for route := range c.Routes {
+		method, path, _ := strings.Cut(route, " ")
+		p.check(method != "" && method == strings.ToUpper(method) && strings.HasPrefix(path, "/"),
+			"rate_limit.routes", "%q is not a route such as \"POST /api/login\"", route)
+	}
+}
+
+func (c NotificationsConfig) validate(p *problems) {
+	if email := c.Email; email.Enabled {
+		p.check(email.SMTPHost != "", "notifications.email.smtp_host", "is required when email is enabled")
+		p.port(email.SMTPPort, "notifications.email.smtp_port")
+		_, err := mail.ParseAddress(email.From)
+		p.check(err == nil, "notifications.email.from", "must be an email address, got %q", email.From)
+	}
+	if sms := c.SMS; sms.Enabled {
+		p.check(sms.Provider != "", "notifications.sms.provider", "is required when SMS is enabled")
+		p.check(sms.APIKey != "", "notifications.sms.api_key", "is required when SMS is enabled")
+		p.check(sms.Sender != "", "notifications.sms.sender", "is required when SMS is enabled")
+	}
+}
+
+func (c TrashConfig) validate(p *problems) {
+	p.check(c.Retention > 0, "trash.retention", "must be positive")
+	p.check(c.PurgeInterval >= 0, "trash.purge_interval", "must not be negative")
+}
+
+func (c FacilityMetricsConfig) validate(p *problems) {
+	// Samples are dropped a whole day at a time
+	p.check(c.Retention >= 24*time.Hour, "facility_metrics.retention", "must be at least 24h")
+	p.check(c.MaintenanceInterval >= 0, "facility_metrics.maintenance_interval", "must not be negative")
+}
+
+func (c TracingConfig) validate(p *problems) {
+	if !c.Enabled {
+		return
+	}
+	p.oneOf(c.Exporter, "tracing.exporter", "otlp", "stdout", "file")
+	switch c.Exporter {
+	case "otlp":
+		u, err := url.Parse(c.Endpoint)
+		p.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"tracing.endpoint", "must be a URL such as http://otel-collector:4318, got %q", c.Endpoint)
+	case "file":
+		p.check(c.File != "", "tracing.file", "is required with the file exporter")
+	}
+	p.check(c.SampleRatio >= 0 && c.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %v", c.SampleRatio)
+	p.check(c.ServiceName != "", "tracing.service_name", "is required")
+}
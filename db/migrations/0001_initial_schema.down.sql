@@ -0,0 +1,44 @@
+-- ======================================
+-- 1) Drop materialized view
+-- ======================================
+DROP MATERIALIZED VIEW IF EXISTS facility_stats CASCADE;
+
+-- ======================================
+-- 2) Drop tables (triggers and indexes are removed with their tables)
+-- ======================================
+DROP TABLE IF EXISTS verification_tokens CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS facility_appointments CASCADE;
+DROP TABLE IF EXISTS audit_log CASCADE;
+DROP TABLE IF EXISTS facility_plans CASCADE;
+DROP TABLE IF EXISTS plans CASCADE;
+DROP TABLE IF EXISTS facility_certifications CASCADE;
+DROP TABLE IF EXISTS facility_insurance_providers CASCADE;
+DROP TABLE IF EXISTS facility_operating_hours CASCADE;
+DROP TABLE IF EXISTS facility_equipment CASCADE;
+DROP TABLE IF EXISTS facility_departments CASCADE;
+DROP TABLE IF EXISTS reviews CASCADE;
+DROP TABLE IF EXISTS doctors CASCADE;
+DROP TABLE IF EXISTS facilities CASCADE;
+DROP TABLE IF EXISTS cities CASCADE;
+DROP TABLE IF EXISTS facility_categories CASCADE;
+
+-- ======================================
+-- 3) Drop functions
+-- ======================================
+DROP FUNCTION IF EXISTS refresh_facility_stats CASCADE;
+DROP FUNCTION IF EXISTS log_audit CASCADE;
+
+-- ======================================
+-- 4) Drop ENUM types
+-- ======================================
+DROP TYPE IF EXISTS appointment_status CASCADE;
+DROP TYPE IF EXISTS facility_type CASCADE;
+
+-- ======================================
+-- 5) Drop extensions
+-- ======================================
+DROP EXTENSION IF EXISTS pg_trgm CASCADE;
+DROP EXTENSION IF EXISTS btree_gist CASCADE;
+DROP EXTENSION IF EXISTS "uuid-ossp" CASCADE;
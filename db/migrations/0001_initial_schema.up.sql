@@ -276,29 +276,45 @@ FOR EACH STATEMENT EXECUTE FUNCTION refresh_facility_stats();
 -- ======================================
 -- The 'audit_log' table records changes to key tables for auditing purposes.
 CREATE TABLE audit_log (
-    id BIGINT PRIMARY KEY,
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     table_name VARCHAR(50),
     operation VARCHAR(10),
     old_data JSONB,
     new_data JSONB,
-    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    changed_by BIGINT,          -- app.user_id of the transaction that made the change
+    request_id VARCHAR(64),     -- app.request_id of the HTTP request that made the change
+    reason TEXT                 -- app.reason, set for operations such as point-in-time restores
 );
 
+-- Supports audit queries by table/time and by the record ID stored inside the row snapshots.
+CREATE INDEX idx_audit_log_table_changed_at ON audit_log(table_name, changed_at);
+CREATE INDEX idx_audit_log_record_id ON audit_log(table_name, (COALESCE(new_data->>'id', old_data->>'id')));
+CREATE INDEX idx_audit_log_facility_id ON audit_log((COALESCE(new_data->>'facility_id', old_data->>'facility_id')));
+CREATE INDEX idx_audit_log_changed_by ON audit_log(changed_by);
+CREATE INDEX idx_audit_log_request_id ON audit_log(request_id);
+
 -- Function to log audit information
+-- The acting user and request are read from the transaction-local settings app.user_id and
+-- app.request_id (plus an optional app.reason), which the repository layer sets with SET LOCAL / set_config(..., true).
 CREATE OR REPLACE FUNCTION log_audit()
 RETURNS TRIGGER AS $$
+DECLARE
+    actor_id BIGINT := NULLIF(current_setting('app.user_id', true), '')::BIGINT;
+    req_id VARCHAR(64) := NULLIF(current_setting('app.request_id', true), '');
+    change_reason TEXT := NULLIF(current_setting('app.reason', true), '');
 BEGIN
     IF (TG_OP = 'DELETE') THEN
-        INSERT INTO audit_log (table_name, operation, old_data, changed_at)
-        VALUES (TG_TABLE_NAME, TG_OP, row_to_json(OLD), CURRENT_TIMESTAMP);
+        INSERT INTO audit_log (table_name, operation, old_data, changed_at, changed_by, request_id, reason)
+        VALUES (TG_TABLE_NAME, TG_OP, row_to_json(OLD), CURRENT_TIMESTAMP, actor_id, req_id, change_reason);
         RETURN OLD;
     ELSIF (TG_OP = 'INSERT') THEN
-        INSERT INTO audit_log (table_name, operation, new_data, changed_at)
-        VALUES (TG_TABLE_NAME, TG_OP, row_to_json(NEW), CURRENT_TIMESTAMP);
+        INSERT INTO audit_log (table_name, operation, new_data, changed_at, changed_by, request_id, reason)
+        VALUES (TG_TABLE_NAME, TG_OP, row_to_json(NEW), CURRENT_TIMESTAMP, actor_id, req_id, change_reason);
         RETURN NEW;
     ELSIF (TG_OP = 'UPDATE') THEN
-        INSERT INTO audit_log (table_name, operation, old_data, new_data, changed_at)
-        VALUES (TG_TABLE_NAME, TG_OP, row_to_json(OLD), row_to_json(NEW), CURRENT_TIMESTAMP);
+        INSERT INTO audit_log (table_name, operation, old_data, new_data, changed_at, changed_by, request_id, reason)
+        VALUES (TG_TABLE_NAME, TG_OP, row_to_json(OLD), row_to_json(NEW), CURRENT_TIMESTAMP, actor_id, req_id, change_reason);
         RETURN NEW;
     END IF;
     RETURN NULL;
@@ -352,9 +368,10 @@ CREATE TABLE plans (
 --   (You may also do this in separate insert statements or via your app)
 -- ======================================
 -- Inserts initial subscription plans into the 'plans' table.
-INSERT INTO plans (name, monthly_price, yearly_price, description, features)
+INSERT INTO plans (id, name, monthly_price, yearly_price, description, features)
 VALUES
 (
+    1,
     'Basic',
     39,            -- Monthly price
     390,           -- Yearly price
@@ -362,6 +379,7 @@ VALUES
     '["Standard profile", "Basic search listing", "Basic analytics (views, clicks)"]'::jsonb
 ),
 (
+    2,
     'Pro',
     99,            -- Monthly price
     990,           -- Yearly price
@@ -369,6 +387,7 @@ VALUES
     '["Enhanced profile (photos and videos)", "Featured placement in search", "Advanced analytics dashboard"]'::jsonb
 ),
 (
+    3,
     'Enterprise',
     190,           -- Monthly price
     1900,          -- Yearly price
@@ -456,7 +475,7 @@ CREATE TABLE users (
     phone_number VARCHAR(20) DEFAULT NULL,
     password VARCHAR(255),
     email_verified TIMESTAMPTZ DEFAULT NULL,
-    image TEXT
+    image TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -467,19 +486,25 @@ CREATE TABLE users (
 -- The 'sessions' table manages user session data for authentication and tracking.
 CREATE TABLE sessions (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
-    "userId" BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
     expires TIMESTAMPTZ NOT NULL,
-    "sessionToken" VARCHAR(255) NOT NULL,
-    CONSTRAINT fk_user FOREIGN KEY ("userId") REFERENCES users(id) ON DELETE CASCADE
+    session_token VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
 -- ======================================
--- 25) Create verification_token table
+-- 25) Create verification_tokens table
 -- ======================================
--- The 'verification_token' table stores tokens for verifying user identities or actions.
+-- The 'verification_tokens' table stores tokens for verifying user identities or actions.
 -- It includes details about the token, expiration time, and an associated identifier.
-CREATE TABLE verification_token (
+CREATE TABLE verification_tokens (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    identifier VARCHAR(255) NOT NULL,
     token TEXT NOT NULL,
-    expires TIMESTAMPTZ NOT NULL
+    expires TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (identifier, token)
 );
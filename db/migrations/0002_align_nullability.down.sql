@@ -0,0 +1,51 @@
+-- Revert 0002: drop the NOT NULL constraints added to match the Go models.
+ALTER TABLE users
+    ALTER COLUMN name DROP NOT NULL,
+    ALTER COLUMN email DROP NOT NULL,
+    ALTER COLUMN password DROP NOT NULL,
+    ALTER COLUMN image DROP NOT NULL,
+    ALTER COLUMN image DROP DEFAULT;
+
+ALTER TABLE facility_appointments
+    ALTER COLUMN facility_id DROP NOT NULL,
+    ALTER COLUMN status DROP NOT NULL;
+ALTER TABLE facility_plans
+    ALTER COLUMN facility_id DROP NOT NULL,
+    ALTER COLUMN plan_id DROP NOT NULL,
+    ALTER COLUMN is_active DROP NOT NULL;
+ALTER TABLE facility_certifications ALTER COLUMN facility_id DROP NOT NULL;
+ALTER TABLE facility_operating_hours
+    ALTER COLUMN facility_id DROP NOT NULL,
+    ALTER COLUMN day_of_week DROP NOT NULL,
+    ALTER COLUMN is_closed DROP NOT NULL,
+    ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE facility_equipment ALTER COLUMN facility_id DROP NOT NULL;
+ALTER TABLE facility_departments ALTER COLUMN facility_id DROP NOT NULL;
+
+ALTER TABLE facilities
+    ALTER COLUMN is_24_hours DROP NOT NULL,
+    ALTER COLUMN has_emergency DROP NOT NULL,
+    ALTER COLUMN has_parking DROP NOT NULL,
+    ALTER COLUMN has_ambulance DROP NOT NULL,
+    ALTER COLUMN accepts_insurance DROP NOT NULL;
+
+ALTER TABLE audit_log
+    ALTER COLUMN changed_at DROP NOT NULL,
+    ALTER COLUMN table_name DROP NOT NULL,
+    ALTER COLUMN operation DROP NOT NULL;
+
+ALTER TABLE facility_categories ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE cities ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facilities ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE doctors ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE reviews ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facility_departments ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facility_equipment ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facility_insurance_providers ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facility_certifications ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE plans ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facility_plans ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE facility_appointments ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE sessions ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE verification_tokens ALTER COLUMN created_at DROP NOT NULL, ALTER COLUMN updated_at DROP NOT NULL;
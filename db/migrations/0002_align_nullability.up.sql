@@ -0,0 +1,122 @@
+-- ======================================
+-- Align nullability with the Go models
+-- ======================================
+-- Columns that always have a value (defaulted timestamps and flags, owning foreign keys)
+-- were declared nullable, which made scanning into non-pointer model fields fail at runtime.
+-- Backfill any NULLs with the column default before adding the constraint.
+
+-- 1) created_at / updated_at are always set by their defaults
+UPDATE facility_categories SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_categories SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_categories ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE cities SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE cities SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE cities ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facilities SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facilities SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facilities ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE doctors SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE doctors SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE doctors ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE reviews SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE reviews SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE reviews ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_departments SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_departments SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_departments ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_equipment SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_equipment SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_equipment ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_insurance_providers SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_insurance_providers SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_insurance_providers ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_certifications SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_certifications SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_certifications ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE plans SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE plans SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE plans ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_plans SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_plans SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_plans ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_appointments SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE facility_appointments SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE facility_appointments ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE users SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE sessions SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE sessions SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE sessions ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE verification_tokens SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE verification_tokens SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+ALTER TABLE verification_tokens ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE facility_operating_hours SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE facility_operating_hours ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE audit_log SET changed_at = CURRENT_TIMESTAMP WHERE changed_at IS NULL;
+ALTER TABLE audit_log
+    ALTER COLUMN changed_at SET NOT NULL,
+    ALTER COLUMN table_name SET NOT NULL,
+    ALTER COLUMN operation SET NOT NULL;
+
+-- 2) Boolean flags default to false/true and have no "unknown" state
+UPDATE facilities SET
+    is_24_hours = COALESCE(is_24_hours, false),
+    has_emergency = COALESCE(has_emergency, false),
+    has_parking = COALESCE(has_parking, false),
+    has_ambulance = COALESCE(has_ambulance, false),
+    accepts_insurance = COALESCE(accepts_insurance, false)
+WHERE is_24_hours IS NULL OR has_emergency IS NULL OR has_parking IS NULL
+   OR has_ambulance IS NULL OR accepts_insurance IS NULL;
+ALTER TABLE facilities
+    ALTER COLUMN is_24_hours SET NOT NULL,
+    ALTER COLUMN has_emergency SET NOT NULL,
+    ALTER COLUMN has_parking SET NOT NULL,
+    ALTER COLUMN has_ambulance SET NOT NULL,
+    ALTER COLUMN accepts_insurance SET NOT NULL;
+
+UPDATE facility_operating_hours SET is_closed = false WHERE is_closed IS NULL;
+ALTER TABLE facility_operating_hours ALTER COLUMN is_closed SET NOT NULL;
+
+UPDATE facility_plans SET is_active = true WHERE is_active IS NULL;
+ALTER TABLE facility_plans ALTER COLUMN is_active SET NOT NULL;
+
+UPDATE facility_appointments SET status = 'Scheduled' WHERE status IS NULL;
+ALTER TABLE facility_appointments ALTER COLUMN status SET NOT NULL;
+
+-- 3) Rows that cannot exist without their owning facility
+ALTER TABLE facility_departments ALTER COLUMN facility_id SET NOT NULL;
+ALTER TABLE facility_equipment ALTER COLUMN facility_id SET NOT NULL;
+ALTER TABLE facility_operating_hours
+    ALTER COLUMN facility_id SET NOT NULL,
+    ALTER COLUMN day_of_week SET NOT NULL;
+ALTER TABLE facility_certifications ALTER COLUMN facility_id SET NOT NULL;
+ALTER TABLE facility_plans
+    ALTER COLUMN facility_id SET NOT NULL,
+    ALTER COLUMN plan_id SET NOT NULL;
+ALTER TABLE facility_appointments ALTER COLUMN facility_id SET NOT NULL;
+
+-- 4) Users always register with a name, email and password
+UPDATE users SET image = '' WHERE image IS NULL;
+ALTER TABLE users
+    ALTER COLUMN name SET NOT NULL,
+    ALTER COLUMN email SET NOT NULL,
+    ALTER COLUMN password SET NOT NULL,
+    ALTER COLUMN image SET DEFAULT '',
+    ALTER COLUMN image SET NOT NULL;
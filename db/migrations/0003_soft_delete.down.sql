@@ -0,0 +1,29 @@
+-- Revert 0003: restore the original facility_stats and drop deleted_at.
+-- Rows still in the trash become visible again.
+DROP MATERIALIZED VIEW facility_stats;
+
+CREATE MATERIALIZED VIEW facility_stats AS
+SELECT
+    f.id AS facility_id,
+    f.name AS facility_name,
+    f.type,
+    f.city_id,
+    COUNT(DISTINCT fd.id) AS department_count,
+    COUNT(DISTINCT d.id) AS doctor_count,
+    COUNT(DISTINCT r.id) AS review_count,
+    COALESCE(AVG(r.rating), 0) AS avg_rating,
+    COUNT(DISTINCT fe.id) AS equipment_count,
+    COUNT(DISTINCT fc.id) AS certification_count
+FROM facilities f
+LEFT JOIN facility_departments fd ON fd.facility_id = f.id
+LEFT JOIN doctors d ON d.primary_facility_id = f.id
+LEFT JOIN reviews r ON r.entity_type = 'facility' AND r.entity_id = f.id
+LEFT JOIN facility_equipment fe ON fe.facility_id = f.id
+LEFT JOIN facility_certifications fc ON fc.facility_id = f.id
+GROUP BY f.id, f.name, f.type, f.city_id;
+
+CREATE UNIQUE INDEX idx_facility_stats_facility_id ON facility_stats (facility_id);
+
+ALTER TABLE reviews DROP COLUMN deleted_at;
+ALTER TABLE doctors DROP COLUMN deleted_at;
+ALTER TABLE facilities DROP COLUMN deleted_at;
@@ -0,0 +1,40 @@
+-- ======================================
+-- Soft deletion of facilities, doctors and reviews
+-- ======================================
+-- Deleting one of these rows through the repositories stamps deleted_at instead of removing it,
+-- so appointments, reviews and audit history keep pointing at a row that can be restored.
+-- Rows are hard-deleted by the purge job once the retention period has passed.
+ALTER TABLE facilities ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE doctors ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE reviews ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Trash listings and the purge job only look at deleted rows.
+CREATE INDEX idx_facilities_deleted_at ON facilities(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_doctors_deleted_at ON doctors(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_reviews_deleted_at ON reviews(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Rebuild facility_stats so deleted rows no longer count.
+DROP MATERIALIZED VIEW facility_stats;
+
+CREATE MATERIALIZED VIEW facility_stats AS
+SELECT
+    f.id AS facility_id,
+    f.name AS facility_name,
+    f.type,
+    f.city_id,
+    COUNT(DISTINCT fd.id) AS department_count,
+    COUNT(DISTINCT d.id) AS doctor_count,
+    COUNT(DISTINCT r.id) AS review_count,
+    COALESCE(AVG(r.rating), 0) AS avg_rating,
+    COUNT(DISTINCT fe.id) AS equipment_count,
+    COUNT(DISTINCT fc.id) AS certification_count
+FROM facilities f
+LEFT JOIN facility_departments fd ON fd.facility_id = f.id
+LEFT JOIN doctors d ON d.primary_facility_id = f.id AND d.deleted_at IS NULL
+LEFT JOIN reviews r ON r.entity_type = 'facility' AND r.entity_id = f.id AND r.deleted_at IS NULL
+LEFT JOIN facility_equipment fe ON fe.facility_id = f.id
+LEFT JOIN facility_certifications fc ON fc.facility_id = f.id
+WHERE f.deleted_at IS NULL
+GROUP BY f.id, f.name, f.type, f.city_id;
+
+CREATE UNIQUE INDEX idx_facility_stats_facility_id ON facility_stats (facility_id);
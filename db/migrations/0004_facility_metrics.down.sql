@@ -0,0 +1,4 @@
+-- Revert 0004: drop facility metrics together with every partition and the samples they hold.
+DROP TABLE facility_metrics;
+DROP FUNCTION create_facility_metrics_partition(DATE);
+DROP TYPE facility_metric_type;
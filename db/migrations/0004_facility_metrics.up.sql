@@ -0,0 +1,42 @@
+-- ======================================
+-- Facility metrics
+-- ======================================
+-- Operational time series reported by facilities: bed occupancy, emergency room wait time and
+-- daily patient count. Samples are keyed by facility, metric and time, so re-sending a sample
+-- overwrites it instead of adding a duplicate.
+CREATE TYPE facility_metric_type AS ENUM (
+    'bed_occupancy',    -- Percentage of beds in use
+    'er_wait_minutes',  -- Expected wait in the emergency room
+    'daily_patients'    -- Patients seen so far that day
+);
+
+-- The table is partitioned by UTC day on measured_at. Queries over a range only scan the days it
+-- covers, and the maintenance job enforces the retention period by dropping whole partitions
+-- instead of deleting rows. There is no default partition: a sample can only be stored once the
+-- partition of its day exists.
+CREATE TABLE facility_metrics (
+    facility_id BIGINT NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    metric_type facility_metric_type NOT NULL,
+    metric_value DOUBLE PRECISION NOT NULL,
+    measured_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (facility_id, metric_type, measured_at)
+) PARTITION BY RANGE (measured_at);
+
+-- Creates the partition holding the samples of a UTC day, named facility_metrics_pYYYYMMDD, unless
+-- it exists. Ingestion calls it for the days of every batch and the maintenance job for the days ahead.
+CREATE OR REPLACE FUNCTION create_facility_metrics_partition(day DATE)
+RETURNS VOID AS $$
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF facility_metrics FOR VALUES FROM (%L) TO (%L)',
+        'facility_metrics_p' || to_char(day, 'YYYYMMDD'),
+        day::TIMESTAMP AT TIME ZONE 'UTC',
+        (day + 1)::TIMESTAMP AT TIME ZONE 'UTC'
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- Partitions for today and the coming week, until the maintenance job takes over.
+SELECT create_facility_metrics_partition(day::DATE)
+FROM generate_series((now() AT TIME ZONE 'UTC')::DATE, (now() AT TIME ZONE 'UTC')::DATE + 7, INTERVAL '1 day') AS day;
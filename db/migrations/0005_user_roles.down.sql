@@ -0,0 +1,3 @@
+-- Revert 0005: drop user roles.
+ALTER TABLE users DROP COLUMN role;
+DROP TYPE user_role;
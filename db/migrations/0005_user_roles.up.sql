@@ -0,0 +1,13 @@
+-- ======================================
+-- User roles
+-- ======================================
+-- Admin routes used to trust an X-User-Role header sent by the client. The role is now stored
+-- with the user and carried in the signed access token instead. Every existing user becomes a
+-- plain user; grant staff or admin explicitly, e.g. UPDATE users SET role = 'admin' WHERE id = 1.
+CREATE TYPE user_role AS ENUM (
+    'user',   -- Patients and other members of the public
+    'staff',  -- Facility staff, who manage appointments
+    'admin'   -- Administrators, who may use every /api/admin route
+);
+
+ALTER TABLE users ADD COLUMN role user_role NOT NULL DEFAULT 'user';
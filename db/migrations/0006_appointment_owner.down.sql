@@ -0,0 +1,3 @@
+-- Revert 0006: drop the owner of appointments.
+DROP INDEX IF EXISTS idx_facility_appointments_booked_by;
+ALTER TABLE facility_appointments DROP COLUMN booked_by;
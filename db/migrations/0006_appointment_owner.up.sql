@@ -0,0 +1,10 @@
+-- ======================================
+-- Appointment owner
+-- ======================================
+-- Appointments used to be booked anonymously, so anyone could cancel any of them. Booking now
+-- requires signing in and records the user who booked; only that user, staff and admins may
+-- cancel the appointment. Appointments booked before have no owner and are left to staff.
+ALTER TABLE facility_appointments
+    ADD COLUMN booked_by BIGINT REFERENCES users(id) ON DELETE SET NULL;  -- NULL once the user is deleted
+
+CREATE INDEX idx_facility_appointments_booked_by ON facility_appointments (booked_by);
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL schema migrations into the binary.
+//
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql, where version is a
+// zero-padded integer that defines the order in which migrations are applied.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
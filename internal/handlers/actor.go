@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"strconv"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditActor builds the audit actor for the current request from the authenticated
+// principal and the request ID set by the middlewares.
+func auditActor(c *gin.Context) repositories.Actor {
+	actor := repositories.Actor{
+		RequestID: c.GetString(middlewares.RequestIDKey),
+		Role:      models.UserRole(c.GetString(middlewares.RoleKey)),
+	}
+	if userID, err := strconv.ParseInt(c.GetString(middlewares.UserIDKey), 10, 64); err == nil {
+		actor.UserID = &userID
+	}
+	return actor
+}
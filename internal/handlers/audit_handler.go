@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidPagination = apperrors.Validation("Invalid limit or offset")
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// RegisterAuditRoutes registers audit log routes on the admin router group.
+func (h *AuditHandler) RegisterAuditRoutes(r *gin.RouterGroup) {
+	r.GET("/audit-logs", h.QueryAuditLog)                  // Search the audit log
+	r.GET("/facilities/:id/history", h.GetFacilityHistory) // Timeline of a facility and its dependent rows
+	r.GET("/facilities/:id", h.GetFacilityAsOf)            // View a facility as of ?as_of=<RFC3339>
+	r.POST("/facilities/:id/restore", h.RestoreFacility)   // Restore a facility to its state as of a timestamp
+}
+
+// restoreFacilityRequest is the body of a point-in-time restore request.
+type restoreFacilityRequest struct {
+	AsOf time.Time `json:"as_of" validate:"required"`
+}
+
+// QueryAuditLog handles audit log searches filtered by table, record ID, operation, changed field,
+// acting user, request ID and time range.
+func (h *AuditHandler) QueryAuditLog(c *gin.Context) {
+	var q repositories.AuditLogQuery
+	var err error
+
+	q.TableName = c.Query("table")
+	q.Operation = c.Query("operation")
+	q.Field = c.Query("field")
+	q.RequestID = c.Query("request_id")
+
+	if recordID := c.Query("record_id"); recordID != "" {
+		id, err := strconv.ParseInt(recordID, 10, 64)
+		if err != nil {
+			respondError(c, apperrors.Validation("Invalid record_id"))
+			return
+		}
+		q.RecordID = &id
+	}
+
+	if changedBy := c.Query("changed_by"); changedBy != "" {
+		id, err := strconv.ParseInt(changedBy, 10, 64)
+		if err != nil {
+			respondError(c, apperrors.Validation("Invalid changed_by"))
+			return
+		}
+		q.ChangedBy = &id
+	}
+
+	if q.From, err = parseTimeQuery(c, "from"); err != nil {
+		respondError(c, apperrors.Validation("Invalid from timestamp, expected RFC3339"))
+		return
+	}
+	if q.To, err = parseTimeQuery(c, "to"); err != nil {
+		respondError(c, apperrors.Validation("Invalid to timestamp, expected RFC3339"))
+		return
+	}
+
+	if q.Limit, q.Offset, err = parsePagination(c); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, err := h.service.QueryAuditLog(c.Request.Context(), q)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// GetFacilityHistory handles requests for the change timeline of a single facility.
+func (h *AuditHandler) GetFacilityHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, err := h.service.GetFacilityHistory(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"facility_id": strconv.FormatInt(id, 10), "history": entries})
+}
+
+// GetFacilityAsOf handles requests for a facility and its dependent rows as of a point in time.
+func (h *AuditHandler) GetFacilityAsOf(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	asOf, err := parseTimeQuery(c, "as_of")
+	if err != nil || asOf == nil {
+		respondError(c, apperrors.Validation("as_of is required as an RFC3339 timestamp"))
+		return
+	}
+
+	snapshot, err := h.service.GetFacilityAsOf(c.Request.Context(), id, *asOf)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"snapshot": snapshot})
+}
+
+// RestoreFacility handles point-in-time restores of a facility from the audit log.
+func (h *AuditHandler) RestoreFacility(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	var req restoreFacilityRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	snapshot, err := h.service.RestoreFacility(c.Request.Context(), auditActor(c), id, req.AsOf)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Facility restored successfully", "snapshot": snapshot})
+}
+
+// parseTimeQuery reads an optional RFC3339 timestamp from the query string.
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parsePagination reads the optional limit and offset query parameters.
+func parsePagination(c *gin.Context) (int, int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		return 0, 0, errInvalidPagination
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, errInvalidPagination
+	}
+	return limit, offset, nil
+}
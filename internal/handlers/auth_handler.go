@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"server/internal/services"
 	"server/internal/validators"
+	"server/pkg/apperrors"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,13 +31,12 @@ func (h *AuthHandler) RegisterAuthRoutes(r *gin.RouterGroup) {
 // RegisterUser handles user registration requests
 func (h *AuthHandler) RegisterUser(c *gin.Context) {
 	var req validators.TRegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if !bindJSON(c, &req) {
 		return
 	}
-	user, err := h.service.CreateUser(&req)
+	user, err := h.service.CreateUser(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"user": user})
@@ -45,26 +45,21 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 // LoginUser handles user login requests
 func (h *AuthHandler) LoginUser(c *gin.Context) {
 	var req validators.TLoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	// Attempt to authenticate the user
-	user, err := h.service.LoginUser(&req)
+	user, err := h.service.LoginUser(c.Request.Context(), &req)
 	if err != nil {
-		if err.Error() == "invalid credentials" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		respondError(c, err)
 		return
 	}
 
 	// Generate a session or JWT token for the user
 	token, err := h.service.GenerateAuthToken(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication token"})
+		respondError(c, err)
 		return
 	}
 
@@ -81,14 +76,14 @@ func (h *AuthHandler) GetAuthenticatedUser(c *gin.Context) {
 	// For now, we will mock the user retrieval
 	userID := c.GetString("user_id") // Assuming you've set the user ID in the context earlier
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		respondError(c, apperrors.Unauthorized("Authentication required"))
 		return
 	}
 
 	// Fetch the user from the database using the service (or session)
-	user, err := h.service.GetUserByID(userID)
+	user, err := h.service.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		respondError(c, err)
 		return
 	}
 
@@ -106,13 +101,12 @@ func (h *AuthHandler) LogoutUser(c *gin.Context) {
 // CreateVerificationToken creates a token for email/phone verification
 func (h *AuthHandler) CreateVerificationToken(c *gin.Context) {
 	var req validators.TVerificationToken
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if !bindJSON(c, &req) {
 		return
 	}
-	token, err := h.service.CreateVerificationToken(&req)
+	token, err := h.service.CreateVerificationToken(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"token": token})
@@ -124,14 +118,8 @@ func (h *AuthHandler) UseVerificationToken(c *gin.Context) {
 	token := c.Param("token")
 
 	// Logic to verify the token
-	isVerified, err := h.service.VerifyToken(identifier, token)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token verification failed"})
-		return
-	}
-
-	if !isVerified {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+	if _, err := h.service.VerifyToken(c.Request.Context(), identifier, token); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -28,13 +28,13 @@ func (h *CityHandler) GetCityByID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseInt(idParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		respondError(c, errInvalidID)
 		return
 	}
 
-	city, err := h.service.GetCityByID(id)
+	city, err := h.service.GetCityByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
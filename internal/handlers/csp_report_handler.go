@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"server/pkg/apperrors"
+	"server/pkg/logger"
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxCSPReportSize bounds the body of a violation report; real reports are a few hundred bytes and
+// Reporting API batches a few kilobytes.
+const maxCSPReportSize = 16 << 10
+
+// cspViolation is a violation as sent by browsers in the legacy report-uri format.
+type cspViolation struct {
+	DocumentURI        string `json:"document-uri"`
+	BlockedURI         string `json:"blocked-uri"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effective-directive"`
+	Disposition        string `json:"disposition"`
+	SourceFile         string `json:"source-file"`
+	LineNumber         int    `json:"line-number"`
+}
+
+// reportingAPIReport is a report as sent by browsers implementing the Reporting API.
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+	} `json:"body"`
+}
+
+// RegisterCSPReportRoutes registers the collector of Content-Security-Policy violation reports at
+// the root of the router, outside /api. The collector takes unauthenticated reports, so guards
+// such as the API rate limiter are passed in to run ahead of it.
+func RegisterCSPReportRoutes(r gin.IRouter, guards ...gin.HandlerFunc) {
+	r.POST(middlewares.CSPReportPath, append(slices.Clip(guards), ReportCSPViolation)...)
+}
+
+// ReportCSPViolation logs the violations reported by a browser, in either the report-uri or the
+// Reporting API format, and answers 204 No Content. A report is logged as a single line however
+// many violations it batches, with the details of the first.
+func ReportCSPViolation(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCSPReportSize))
+	if err != nil {
+		_ = c.Error(apperrors.Validation("The CSP report is too large or could not be read"))
+		return
+	}
+
+	violations, err := parseCSPReport(c.ContentType(), body)
+	if err != nil {
+		_ = c.Error(apperrors.Validation("The CSP report is not valid JSON"))
+		return
+	}
+	if len(violations) > 0 {
+		v := violations[0]
+		directive := v.EffectiveDirective
+		if directive == "" {
+			directive = v.ViolatedDirective
+		}
+		logger.FromContext(c.Request.Context()).Warn("Content-Security-Policy violation",
+			zap.Int("violations", len(violations)),
+			zap.String("directive", directive),
+			zap.String("blocked_uri", v.BlockedURI),
+			zap.String("document_uri", v.DocumentURI),
+			zap.String("disposition", v.Disposition),
+			zap.String("source_file", v.SourceFile),
+			zap.Int("line_number", v.LineNumber),
+			zap.String("user_agent", c.Request.UserAgent()),
+		)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func parseCSPReport(contentType string, body []byte) ([]cspViolation, error) {
+	if contentType == "application/reports+json" {
+		var reports []reportingAPIReport
+		if err := json.Unmarshal(body, &reports); err != nil {
+			return nil, err
+		}
+		violations := make([]cspViolation, 0, len(reports))
+		for _, r := range reports {
+			if !strings.HasPrefix(r.Type, "csp-violation") {
+				continue
+			}
+			violations = append(violations, cspViolation{
+				DocumentURI:        r.Body.DocumentURL,
+				BlockedURI:         r.Body.BlockedURL,
+				EffectiveDirective: r.Body.EffectiveDirective,
+				Disposition:        r.Body.Disposition,
+				SourceFile:         r.Body.SourceFile,
+				LineNumber:         r.Body.LineNumber,
+			})
+		}
+		return violations, nil
+	}
+
+	var report struct {
+		Violation cspViolation `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, err
+	}
+	return []cspViolation{report.Violation}, nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errIfMatchRequired = apperrors.New(apperrors.KindPreconditionRequired, "If-Match header is required")
+	errIfMatchFailed   = apperrors.New(apperrors.KindPreconditionFailed, "If-Match does not match the current version")
+)
+
+// versionETag formats a row's updated_at as a strong ETag, e.g. "5z1k9q3g0w".
+func versionETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// ifMatchVersion reads the row version required by the If-Match header. It returns nil for
+// "If-Match: *", which matches any version, errIfMatchRequired when the header is missing and
+// errIfMatchFailed for tags that cannot match a version, such as weak or foreign tags.
+func ifMatchVersion(c *gin.Context) (*time.Time, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return nil, errIfMatchRequired
+	}
+	if header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`)
+	if len(tag)+2 != len(header) {
+		return nil, errIfMatchFailed
+	}
+	micros, err := strconv.ParseInt(tag, 36, 64)
+	if err != nil {
+		return nil, errIfMatchFailed
+	}
+
+	version := time.UnixMicro(micros)
+	return &version, nil
+}
@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"server/internal/models"
+	"server/internal/repositories"
 	"server/internal/services"
 	"server/internal/validators"
+	"server/pkg/apperrors"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxFacilitySearchLimit caps the page size of facility searches.
+const maxFacilitySearchLimit = 100
+
 type FacilityHandler struct {
 	service *services.FacilityService
 }
@@ -19,12 +28,9 @@ func NewFacilityHandler(service *services.FacilityService) *FacilityHandler {
 
 // RegisterFacilityRoutes registers facility-related routes.
 func (h *FacilityHandler) RegisterFacilityRoutes(r *gin.RouterGroup) {
-	// Basic CRUD routes for facilities
-	r.GET("/facilities", h.GetAllFacilities)          // Fetch all facilities
-	r.GET("/facilities/:id", h.GetFacilityByID)       // Fetch a facility by ID
-	r.POST("/facilities", h.CreateFacility)           // Create a new facility
-	r.PUT("/facilities/:id", h.UpdateFacilityByID)    // Update a facility by ID
-	r.DELETE("/facilities/:id", h.DeleteFacilityByID) // Delete a facility by ID
+	// Basic read routes for facilities
+	r.GET("/facilities", h.GetAllFacilities)    // Fetch all facilities
+	r.GET("/facilities/:id", h.GetFacilityByID) // Fetch a facility by ID
 
 	// Routes for facilities by specific attributes
 	r.GET("/facilities/city/:id", h.GetFacilitiesByCityID)                       // Fetch facilities by city ID
@@ -38,71 +44,161 @@ func (h *FacilityHandler) RegisterFacilityRoutes(r *gin.RouterGroup) {
 
 	// Facility reviews
 	r.GET("/facilities/:id/reviews", h.GetFacilityReviews) // Fetch reviews for a facility
-	r.POST("/facilities/:id/review", h.AddFacilityReview)  // Add a review to a facility
+
+	// Facility doctors
+	r.GET("/facilities/:id/doctors", h.GetFacilityDoctors) // Fetch doctors for a facility
+
+	// Facility appointments
+	r.GET("/facilities/:id/appointments/slots", h.GetFacilityAppointmentSlots) // Fetch available appointment slots
+
+	// Additional routes
+	r.GET("/facilities/search", h.SearchFacilities)    // Search for facilities
+	r.GET("/facilities/nearby", h.GetFacilitiesNearby) // Fetch nearby facilities
+}
+
+// RegisterFacilityWriteRoutes registers the appointment and review routes on a router group that
+// authenticates the caller, so appointments and reviews belong to their user.
+func (h *FacilityHandler) RegisterFacilityWriteRoutes(r *gin.RouterGroup) {
+	// Facility appointments and reviews of the signed-in user
+	r.POST("/facilities/:id/appointments", h.BookFacilityAppointment)                    // Book an appointment at a facility
+	r.DELETE("/facilities/:id/appointments/:appointmentId", h.CancelFacilityAppointment) // Cancel an appointment
+	r.POST("/facilities/:id/review", h.AddFacilityReview)                                // Add a review to a facility
+}
+
+// RegisterFacilityStaffRoutes registers the routes that manage facilities on a router group that
+// admits staff and admins, so the audit log records who made every change.
+func (h *FacilityHandler) RegisterFacilityStaffRoutes(r *gin.RouterGroup) {
+	// Basic write routes for facilities
+	r.POST("/facilities", h.CreateFacility)           // Create a new facility
+	r.PUT("/facilities/:id", h.UpdateFacilityByID)    // Update a facility by ID
+	r.PATCH("/facilities/:id", h.PatchFacilityByID)   // Partially update a facility by ID
+	r.DELETE("/facilities/:id", h.DeleteFacilityByID) // Delete a facility by ID
 
 	// Facility services and doctors
-	r.GET("/facilities/:id/doctors", h.GetFacilityDoctors)                    // Fetch doctors for a facility
 	r.POST("/facilities/:id/doctors", h.AssignDoctorToFacility)               // Assign a doctor to a facility
 	r.PUT("/facilities/:id/services", h.UpdateFacilityServices)               // Update services for a facility
 	r.DELETE("/facilities/:id/doctors/:doctorId", h.RemoveDoctorFromFacility) // Remove a doctor from a facility
 
 	// Facility appointments
-	r.GET("/facilities/:id/appointments/slots", h.GetFacilityAppointmentSlots)           // Fetch available appointment slots
-	r.POST("/facilities/:id/appointments", h.BookFacilityAppointment)                    // Book an appointment at a facility
-	r.DELETE("/facilities/:id/appointments/:appointmentId", h.CancelFacilityAppointment) // Cancel an appointment
+	r.POST("/facilities/:id/appointments/:appointmentId/no-show", h.MarkAppointmentNoShow) // Record that the patient did not come
+}
 
-	// Additional routes
-	r.GET("/facilities/search", h.SearchFacilities)    // Search for facilities
-	r.GET("/facilities/nearby", h.GetFacilitiesNearby) // Fetch nearby facilities
+// RegisterFacilityAdminRoutes registers the facility routes reserved for admins on the admin router
+// group. They include trashed facilities when asked with ?include_deleted=true.
+func (h *FacilityHandler) RegisterFacilityAdminRoutes(r *gin.RouterGroup) {
+	r.GET("/facilities", includeDeleted, h.GetAllFacilities) // Fetch all facilities
 }
 
 // CRUD Operations
 func (h *FacilityHandler) GetAllFacilities(c *gin.Context) {
-	facilities, err := h.service.GetAllFacilities()
+	facilities, err := h.service.GetAllFacilities(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, facilities)
 }
 
+// GetFacilityByID returns a facility with an ETag to send back as If-Match when updating it.
 func (h *FacilityHandler) GetFacilityByID(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Facility fetched successfully"})
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	facility, err := h.service.GetFacilityByID(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(facility.UpdatedAt))
+	c.JSON(http.StatusOK, gin.H{"facility": facility})
 }
 
+// CreateFacility creates a facility and its operating hours, answering with an ETag like GetFacilityByID.
 func (h *FacilityHandler) CreateFacility(c *gin.Context) {
-	var facilityRequest validators.FacilityRequest
-
-	// Bind the incoming JSON to the FacilityRequest struct
-	if err := c.ShouldBindJSON(&facilityRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req validators.CreateFacilityRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// Validate the request using the validation function
-	validators.ValidateFacilityRequest(c, facilityRequest)
+	details, err := h.service.CreateFacilityWithDetails(c.Request.Context(), auditActor(c), req.Details())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
-	// If validation passes, proceed with creating the facility (this part needs implementation)
-	c.JSON(http.StatusOK, gin.H{"message": "Facility created successfully"})
+	c.Header("ETag", versionETag(details.Facility.UpdatedAt))
+	c.JSON(http.StatusCreated, gin.H{"facility": details})
 }
 
+// UpdateFacilityByID replaces a facility's editable fields. The If-Match header must carry the
+// ETag of the version being edited, so concurrent edits fail with 412 instead of overwriting each other.
 func (h *FacilityHandler) UpdateFacilityByID(c *gin.Context) {
 	var facilityRequest validators.FacilityRequest
+	if !bindJSON(c, &facilityRequest) {
+		return
+	}
 
-	// Bind the incoming JSON to the FacilityRequest struct
-	if err := c.ShouldBindJSON(&facilityRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	h.updateFacility(c, facilityRequest.Updates())
+}
+
+// PatchFacilityByID updates the facility fields present in the request, guarded by If-Match like PUT.
+func (h *FacilityHandler) PatchFacilityByID(c *gin.Context) {
+	var patchRequest validators.FacilityPatchRequest
+	if !bindJSON(c, &patchRequest) {
 		return
 	}
 
-	// Validate the request using the validation function
-	validators.ValidateFacilityRequest(c, facilityRequest)
+	updates := patchRequest.Updates()
+	if len(updates) == 0 {
+		respondError(c, apperrors.Validation("No fields to update"))
+		return
+	}
 
-	// If validation passes, proceed with updating the facility (this part needs implementation)
-	c.JSON(http.StatusOK, gin.H{"message": "Facility updated successfully"})
+	h.updateFacility(c, updates)
 }
 
+// updateFacility applies updates to the facility named in the path under the If-Match precondition.
+func (h *FacilityHandler) updateFacility(c *gin.Context, updates map[string]interface{}) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	facility, err := h.service.UpdateFacility(c.Request.Context(), auditActor(c), id, version, updates)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(facility.UpdatedAt))
+	c.JSON(http.StatusOK, gin.H{"facility": facility})
+}
+
+// DeleteFacilityByID moves a facility to the trash.
 func (h *FacilityHandler) DeleteFacilityByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	if err := h.service.DeleteFacility(c.Request.Context(), auditActor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Facility deleted successfully"})
 }
 
@@ -137,8 +233,26 @@ func (h *FacilityHandler) GetFacilityReviews(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Facility reviews fetched successfully"})
 }
 
+// AddFacilityReview stores a review of the facility by the authenticated user, if any.
 func (h *FacilityHandler) AddFacilityReview(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Facility review added successfully"})
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	var req validators.ReviewRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	review, err := h.service.AddFacilityReview(c.Request.Context(), auditActor(c), id, *req.Rating, req.Comment)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"review": review})
 }
 
 // Facility Services and Doctors
@@ -163,17 +277,97 @@ func (h *FacilityHandler) GetFacilityAppointmentSlots(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Facility appointment slots fetched successfully"})
 }
 
+// BookFacilityAppointment schedules an appointment at the facility.
 func (h *FacilityHandler) BookFacilityAppointment(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Facility appointment booked successfully"})
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	var req validators.AppointmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	appointment, err := h.service.BookAppointment(c.Request.Context(), auditActor(c), id, req.Appointment())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"appointment": appointment})
 }
 
+// CancelFacilityAppointment cancels a scheduled appointment at the facility.
 func (h *FacilityHandler) CancelFacilityAppointment(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Facility appointment canceled successfully"})
+	h.closeAppointment(c, h.service.CancelAppointment)
+}
+
+// MarkAppointmentNoShow records that the patient of an appointment at the facility did not come.
+func (h *FacilityHandler) MarkAppointmentNoShow(c *gin.Context) {
+	h.closeAppointment(c, h.service.MarkAppointmentNoShow)
+}
+
+// closeAppointment closes the appointment named in the path with closeWith.
+func (h *FacilityHandler) closeAppointment(c *gin.Context, closeWith func(context.Context, repositories.Actor, int64, int64) (*models.FacilityAppointment, error)) {
+	facilityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	appointmentID, err := strconv.ParseInt(c.Param("appointmentId"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	appointment, err := closeWith(c.Request.Context(), auditActor(c), facilityID, appointmentID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appointment": appointment})
 }
 
 // Additional Routes
+// SearchFacilities searches facilities by ?q= in their name and location, optionally narrowed by
+// ?type= and ?city_id=, and paginated with ?limit= and ?offset=.
 func (h *FacilityHandler) SearchFacilities(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Facilities search results fetched successfully"})
+	q := repositories.FacilitySearch{
+		Term: strings.TrimSpace(c.Query("q")),
+		Type: models.FacilityType(c.Query("type")),
+	}
+	if q.Type != "" && !q.Type.Valid() {
+		respondError(c, apperrors.Validation("Invalid facility type"))
+		return
+	}
+	if cityID := c.Query("city_id"); cityID != "" {
+		id, err := strconv.ParseInt(cityID, 10, 64)
+		if err != nil {
+			respondError(c, apperrors.Validation("Invalid city_id"))
+			return
+		}
+		q.CityID = &id
+	}
+
+	var err error
+	if q.Limit, q.Offset, err = parsePagination(c); err != nil {
+		respondError(c, err)
+		return
+	}
+	if q.Limit > maxFacilitySearchLimit {
+		q.Limit = maxFacilitySearchLimit
+	}
+
+	facilities, err := h.service.SearchFacilities(c.Request.Context(), q)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"facilities": facilities})
 }
 
 func (h *FacilityHandler) GetFacilitiesNearby(c *gin.Context) {
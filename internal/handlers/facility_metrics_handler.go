@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/internal/validators"
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FacilityMetricsHandler struct {
+	service *services.FacilityMetricsService
+}
+
+// NewFacilityMetricsHandler creates a new FacilityMetricsHandler.
+func NewFacilityMetricsHandler(service *services.FacilityMetricsService) *FacilityMetricsHandler {
+	return &FacilityMetricsHandler{service: service}
+}
+
+// RegisterFacilityMetricsRoutes registers the public facility metrics routes.
+func (h *FacilityMetricsHandler) RegisterFacilityMetricsRoutes(r *gin.RouterGroup) {
+	r.GET("/facilities/:id/metrics", h.QueryFacilityMetrics)            // Downsampled metrics over a time range
+	r.GET("/facilities/:id/metrics/latest", h.GetLatestFacilityMetrics) // Latest sample of every metric
+}
+
+// RegisterFacilityMetricsStaffRoutes registers the facility metrics routes reserved for staff on a
+// router group that admits staff and admins.
+func (h *FacilityMetricsHandler) RegisterFacilityMetricsStaffRoutes(r *gin.RouterGroup) {
+	r.POST("/facilities/:id/metrics", h.IngestFacilityMetrics) // Store a batch of samples
+}
+
+// IngestFacilityMetrics stores a batch of metric samples reported by the facility.
+func (h *FacilityMetricsHandler) IngestFacilityMetrics(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	var req validators.FacilityMetricsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	stored, err := h.service.IngestMetrics(c.Request.Context(), id, req.Samples())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"facility_id": strconv.FormatInt(id, 10), "stored": stored})
+}
+
+// QueryFacilityMetrics summarizes the facility's samples measured between ?from= and ?to= in
+// buckets ?bucket= wide, such as 15m, optionally narrowed to the metric ?type=.
+func (h *FacilityMetricsHandler) QueryFacilityMetrics(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	q := repositories.FacilityMetricsQuery{FacilityID: id, Type: models.FacilityMetricType(c.Query("type"))}
+	if q.Type != "" && !q.Type.Valid() {
+		respondError(c, apperrors.Validation("Invalid metric type"))
+		return
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		respondError(c, apperrors.Validation("Invalid from timestamp, expected RFC3339"))
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		respondError(c, apperrors.Validation("Invalid to timestamp, expected RFC3339"))
+		return
+	}
+	if from != nil {
+		q.From = *from
+	}
+	if to != nil {
+		q.To = *to
+	}
+
+	if bucket := c.Query("bucket"); bucket != "" {
+		if q.Bucket, err = time.ParseDuration(bucket); err != nil || q.Bucket <= 0 {
+			respondError(c, apperrors.Validation("Invalid bucket, expected a duration such as 15m"))
+			return
+		}
+	}
+
+	buckets, err := h.service.QueryMetrics(c.Request.Context(), q)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"facility_id": strconv.FormatInt(id, 10), "buckets": buckets})
+}
+
+// GetLatestFacilityMetrics returns the latest sample of every metric the facility reported recently,
+// such as its current emergency room wait.
+func (h *FacilityMetricsHandler) GetLatestFacilityMetrics(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	metrics, err := h.service.LatestMetrics(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"facility_id": strconv.FormatInt(id, 10), "metrics": metrics})
+}
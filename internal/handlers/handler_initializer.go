@@ -2,29 +2,54 @@ package handlers
 
 import (
 	"server/internal/services"
+	"server/pkg/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Services groups all the service instances.
 type Services struct {
-	CityService     *services.CityService
-	FacilityService *services.FacilityService
-	AuthService     *services.AuthService // Add AuthService
+	CityService            *services.CityService
+	FacilityService        *services.FacilityService
+	AuthService            *services.AuthService // Add AuthService
+	AuditService           *services.AuditService
+	TrashService           *services.TrashService
+	FacilityMetricsService *services.FacilityMetricsService
 	// Add other services here as needed
 }
 
 // RegisterHandlers initializes and registers all application handlers with the provided Gin router.
-func RegisterHandlers(router *gin.Engine, services *Services) {
-	api := router.Group("/api")
+// apiMiddlewares run on every route under /api, ahead of route-specific middlewares such as auth.
+func RegisterHandlers(router *gin.Engine, services *Services, apiMiddlewares ...gin.HandlerFunc) {
+	api := router.Group("/api", apiMiddlewares...)
 
 	// Initialize handlers
 	cityHandler := NewCityHandler(services.CityService)
 	facilityHandler := NewFacilityHandler(services.FacilityService)
 	authHandler := NewAuthHandler(services.AuthService) // Initialize the AuthHandler
+	auditHandler := NewAuditHandler(services.AuditService)
+	trashHandler := NewTrashHandler(services.TrashService)
+	facilityMetricsHandler := NewFacilityMetricsHandler(services.FacilityMetricsService)
 
 	// Register routes
 	cityHandler.RegisterCityRoutes(api)
 	facilityHandler.RegisterFacilityRoutes(api)
+	facilityMetricsHandler.RegisterFacilityMetricsRoutes(api)
 	authHandler.RegisterAuthRoutes(api)
+
+	// Routes of signed-in users: booking, cancelling and reviewing
+	authenticated := api.Group("", middlewares.AuthMiddleware(services.AuthService))
+	facilityHandler.RegisterFacilityWriteRoutes(authenticated)
+
+	// Routes that manage facilities, for staff and admins; the audit log attributes changes to them
+	staff := api.Group("", middlewares.AuthMiddleware(services.AuthService), middlewares.RoleBasedAccessControl("staff", "admin"))
+	facilityHandler.RegisterFacilityStaffRoutes(staff)
+	facilityMetricsHandler.RegisterFacilityMetricsStaffRoutes(staff)
+
+	// Admin-only routes
+	admin := api.Group("/admin", middlewares.AuthMiddleware(services.AuthService), middlewares.RoleBasedAccessControl("admin"))
+	auditHandler.RegisterAuditRoutes(admin)
+	trashHandler.RegisterTrashRoutes(admin)
+	facilityHandler.RegisterFacilityAdminRoutes(admin)
+	RegisterSnowflakeRoutes(admin)
 }
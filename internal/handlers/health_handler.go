@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"server/pkg/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves the liveness and readiness probes.
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// RegisterHealthRoutes registers the probes at the root of the router, outside /api.
+func (h *HealthHandler) RegisterHealthRoutes(r gin.IRouter) {
+	r.GET("/healthz", h.Liveness) // The process is up and serving requests
+	r.GET("/readyz", h.Readiness) // The process can handle traffic: dependencies are reachable
+}
+
+// Liveness answers as long as the server can serve requests. It checks no dependencies, so an
+// outage of the database does not get every replica restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readiness checks every dependency and answers 503 when one fails or the server is shutting down.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
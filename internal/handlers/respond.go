@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"server/internal/validators"
+	"server/pkg/apperrors"
+	"server/pkg/middlewares"
+	"server/pkg/sanitize"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// errInvalidID is returned for path IDs that are not integers.
+var errInvalidID = apperrors.Validation("Invalid ID")
+
+// respondError aborts the request with err, which middlewares.ErrorHandler renders as a
+// problem+json response. Services classify their errors with apperrors; anything else is a 500.
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// bindJSON decodes and validates the request body into obj, sanitizing it in between when
+// middlewares.XSSMiddleware is active. Invalid bodies abort the request with a validation error
+// listing each invalid field in the client's language; bindJSON then returns false.
+func bindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindWith(obj, jsonBinding{sanitize: c.GetBool(middlewares.SanitizeBodiesKey)}); err != nil {
+		respondError(c, validators.BindError(err, c.GetHeader("Accept-Language")))
+		return false
+	}
+	return true
+}
+
+// jsonBinding decodes a JSON body like binding.JSON, but sanitizes it before validating it, so rules
+// such as required apply to the text that is stored.
+type jsonBinding struct {
+	sanitize bool
+}
+
+func (jsonBinding) Name() string { return "json" }
+
+func (b jsonBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := json.NewDecoder(req.Body).Decode(obj); err != nil {
+		return err
+	}
+	if b.sanitize {
+		sanitize.Struct(obj)
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
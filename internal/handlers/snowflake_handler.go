@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSnowflakeRoutes registers the ID decoder on the admin router group.
+func RegisterSnowflakeRoutes(r *gin.RouterGroup) {
+	r.GET("/ids/:id", DecodeSnowflakeID) // Show when and on which node an ID was generated
+}
+
+// DecodeSnowflakeID handles requests to split a Snowflake ID into its timestamp, node and sequence.
+func DecodeSnowflakeID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"snowflake": utils.DecodeSnowflakeID(id)})
+}
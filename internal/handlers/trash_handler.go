@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	service *services.TrashService
+}
+
+// NewTrashHandler creates a new TrashHandler.
+func NewTrashHandler(service *services.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// RegisterTrashRoutes registers the trash routes on the admin router group.
+// :entity is one of facilities, doctors or reviews.
+func (h *TrashHandler) RegisterTrashRoutes(r *gin.RouterGroup) {
+	r.GET("/trash/:entity", h.ListTrash)                     // List trashed records, most recently deleted first
+	r.POST("/trash/:entity/:id/restore", h.RestoreFromTrash) // Take a record out of the trash
+}
+
+// includeDeleted lets the reads of requests sent with ?include_deleted=true see trashed records too.
+func includeDeleted(c *gin.Context) {
+	include, err := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
+	if err != nil {
+		respondError(c, apperrors.Validation("Invalid include_deleted, expected true or false"))
+		return
+	}
+	if include {
+		c.Request = c.Request.WithContext(repositories.WithDeleted(c.Request.Context()))
+	}
+	c.Next()
+}
+
+// ListTrash handles requests for the trashed records of an entity.
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	entity := c.Param("entity")
+
+	records, err := h.service.ListTrash(c.Request.Context(), entity)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entity": entity, "records": records})
+}
+
+// RestoreFromTrash handles restores of a trashed record.
+func (h *TrashHandler) RestoreFromTrash(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	record, err := h.service.RestoreFromTrash(c.Request.Context(), auditActor(c), c.Param("entity"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Record restored successfully", "record": record})
+}
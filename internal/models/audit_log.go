@@ -3,10 +3,37 @@ package models
 import "time"
 
 type AuditLog struct {
-	ID        int64          `json:"id"`
-	TableName string         `json:"table_name"`
-	Operation string         `json:"operation"`
-	OldData   map[string]any `json:"old_data"`
-	NewData   map[string]any `json:"new_data"`
-	ChangedAt time.Time      `json:"changed_at"`
+	ID        int64     `json:"id,string" db:"id"`
+	Table     string    `json:"table_name" db:"table_name"`
+	Operation string    `json:"operation" db:"operation"`
+	OldData   JSONMap   `json:"old_data" db:"old_data"`
+	NewData   JSONMap   `json:"new_data" db:"new_data"`
+	ChangedAt time.Time `json:"changed_at" db:"changed_at"`
+	ChangedBy *int64    `json:"changed_by,string" db:"changed_by"`
+	RequestID *string   `json:"request_id" db:"request_id"`
+	Reason    *string   `json:"reason" db:"reason"`
+}
+
+func (AuditLog) TableName() string { return "audit_log" }
+
+// FieldChange describes a single column whose value differs between the old and new row snapshots.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditEntry is an audit log row together with its computed field-level diff.
+type AuditEntry struct {
+	AuditLog
+	Changes []FieldChange `json:"changes"`
+}
+
+// FacilitySnapshot is a facility and its dependent rows reconstructed from the audit log as of a point in time.
+type FacilitySnapshot struct {
+	AsOf           time.Time `json:"as_of"`
+	Facility       JSONMap   `json:"facility"`
+	Departments    []JSONMap `json:"departments"`
+	OperatingHours []JSONMap `json:"operating_hours"`
+	Equipment      []JSONMap `json:"equipment"`
 }
@@ -3,7 +3,42 @@ package models
 import "time"
 
 type BaseModel struct {
-	ID        int64     `json:"id,omitempty" db:"id" sqlx:"primary_key"`
+	ID        int64     `json:"id,string,omitempty" db:"id" sqlx:"primary_key"`
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
 }
+
+// Tabler is implemented by models that are persisted in their own table.
+type Tabler interface {
+	TableName() string
+}
+
+// SnowflakeKeyed is implemented by models whose table has no ID default.
+// Repositories assign them a Snowflake ID on create.
+type SnowflakeKeyed interface {
+	snowflakeKeyed()
+}
+
+// AllTables lists every model persisted in its own table, for tooling such as the schema drift checker.
+func AllTables() []Tabler {
+	return []Tabler{
+		AuditLog{},
+		City{},
+		Doctor{},
+		Facility{},
+		FacilityAppointment{},
+		FacilityCategory{},
+		FacilityCertification{},
+		FacilityDepartment{},
+		FacilityEquipment{},
+		FacilityInsuranceProvider{},
+		FacilityMetrics{},
+		FacilityOperatingHours{},
+		FacilityPlan{},
+		Plan{},
+		Review{},
+		Session{},
+		User{},
+		VerificationToken{},
+	}
+}
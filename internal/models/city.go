@@ -2,8 +2,11 @@ package models
 
 type City struct {
 	BaseModel
-	Name       string `json:"name" db:"name"`
-	Pupulation int    `json:"population" db:"population"`
-	ImageURL   string `json:"image_url" db:"image_url"`
-	Timezone   string `json:"timezone"`
+	Name       string  `json:"name" db:"name"`
+	Population *int    `json:"population" db:"population"`
+	ImageURL   *string `json:"image_url" db:"image_url"`
+	Timezone   *string `json:"timezone" db:"timezone"`
 }
+
+func (City) TableName() string { return "cities" }
+func (City) snowflakeKeyed()   {}
@@ -1,10 +1,16 @@
 package models
 
+import "time"
+
 type Doctor struct {
 	BaseModel
-	Name              string `json:"name"`
-	Specialty         string `json:"specialty"`
-	PrimaryFacilityID *int64 `json:"primary_facility_id,omitempty"`
-	ContactNumber     string `json:"contact_number"`
-	Email             string `json:"email"`
+	Name              string     `json:"name" db:"name"`
+	Specialty         *string    `json:"specialty" db:"specialty"`
+	PrimaryFacilityID *int64     `json:"primary_facility_id,string,omitempty" db:"primary_facility_id"`
+	ContactNumber     *string    `json:"contact_number" db:"contact_number"`
+	Email             *string    `json:"email" db:"email"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
+
+func (Doctor) TableName() string { return "doctors" }
+func (Doctor) snowflakeKeyed()   {}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type FacilityType string
 
 const (
@@ -14,20 +16,36 @@ const (
 	ImagingCenter        FacilityType = "Imaging Center"
 )
 
+// FacilityTypes lists the values of the facility_type enum.
+var FacilityTypes = []FacilityType{
+	PublicHospital, TeachingHospital, PrivateHospital, RehabilitationCenter, MedicalComplex,
+	Clinic, Pharmacy, Laboratory, ImagingCenter,
+}
+
+// Valid reports whether t is one of FacilityTypes.
+func (t FacilityType) Valid() bool {
+	for _, facilityType := range FacilityTypes {
+		if t == facilityType {
+			return true
+		}
+	}
+	return false
+}
+
 type Facility struct {
 	BaseModel
 	Name             string       `json:"name" db:"name"`
 	Type             FacilityType `json:"type" db:"type"`
-	CategoryID       *int64       `json:"category_id" db:"category_id"`
-	CityID           *int64       `json:"city_id" db:"city_id"`
+	CategoryID       *int64       `json:"category_id,string" db:"category_id"`
+	CityID           *int64       `json:"city_id,string" db:"city_id"`
 	Location         string       `json:"location" db:"location"`
 	Coordinates      *string      `json:"coordinates" db:"coordinates"` // Use *string for nullable column
-	Phone            string       `json:"phone" db:"phone"`
+	Phone            *string      `json:"phone" db:"phone"`
 	EmergencyPhone   *string      `json:"emergency_phone" db:"emergency_phone"`
-	Email            string       `json:"email" db:"email"`
+	Email            *string      `json:"email" db:"email"`
 	Website          *string      `json:"website" db:"website"`
 	Rating           *float32     `json:"rating" db:"rating"`
-	BedCapacity      int          `json:"bed_capacity" db:"bed_capacity"`
+	BedCapacity      *int         `json:"bed_capacity" db:"bed_capacity"`
 	Is24Hours        bool         `json:"is_24_hours" db:"is_24_hours"`
 	HasEmergency     bool         `json:"has_emergency" db:"has_emergency"`
 	HasParking       bool         `json:"has_parking" db:"has_parking"`
@@ -38,4 +56,16 @@ type Facility struct {
 	Amenities        *string      `json:"amenities" db:"amenities"`
 	Accreditations   *string      `json:"accreditations" db:"accreditations"`
 	MetaData         *string      `json:"meta_data" db:"meta_data"`
+	DeletedAt        *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+func (Facility) TableName() string { return "facilities" }
+func (Facility) snowflakeKeyed()   {}
+
+// FacilityDetails is a facility together with the dependent rows created alongside it.
+type FacilityDetails struct {
+	Facility           Facility                    `json:"facility"`
+	Departments        []FacilityDepartment        `json:"departments"`
+	OperatingHours     []FacilityOperatingHours    `json:"operating_hours"`
+	InsuranceProviders []FacilityInsuranceProvider `json:"insurance_providers"`
 }
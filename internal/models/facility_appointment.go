@@ -18,10 +18,14 @@ const (
 type FacilityAppointment struct {
 	BaseModel
 	PatientName          string            `json:"patient_name" db:"patient_name"`
-	PatientContact       string            `json:"patient_contact" db:"patient_contact"`
-	FacilityID           int64             `json:"facility_id" db:"facility_id"`
-	DoctorID             int64             `json:"doctor_id" db:"doctor_id"`
+	PatientContact       *string           `json:"patient_contact" db:"patient_contact"`
+	FacilityID           int64             `json:"facility_id,string" db:"facility_id"`
+	DoctorID             *int64            `json:"doctor_id,string" db:"doctor_id"`
 	AppointmentTime      time.Time         `json:"appointment_time" db:"appointment_time"`
 	Status               AppointmentStatus `json:"status" db:"status"`
-	ReasonForAppointment string            `json:"reason_for_appointment" db:"reason_for_appointment"`
+	ReasonForAppointment *string           `json:"reason_for_appointment" db:"reason_for_appointment"`
+	BookedBy             *int64            `json:"booked_by,string,omitempty" db:"booked_by"` // The user who booked; nil for appointments booked before sign-in was required
 }
+
+func (FacilityAppointment) TableName() string { return "facility_appointments" }
+func (FacilityAppointment) snowflakeKeyed()   {}
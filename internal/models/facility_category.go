@@ -2,7 +2,10 @@ package models
 
 type FacilityCategory struct {
 	BaseModel
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	ParentID    *int64 `json:"parent_id,omitempty"`
+	Name        string  `json:"name" db:"name"`
+	Description *string `json:"description" db:"description"`
+	ParentID    *int64  `json:"parent_id,string,omitempty" db:"parent_id"`
 }
+
+func (FacilityCategory) TableName() string { return "facility_categories" }
+func (FacilityCategory) snowflakeKeyed()   {}
@@ -4,11 +4,14 @@ import "time"
 
 type FacilityCertification struct {
 	BaseModel
-	FacilityID       int64      `json:"facility_id"`
-	Name             string     `json:"name"`
-	IssuingAuthority string     `json:"issuing_authority"`
-	IssueDate        *time.Time `json:"issue_date,omitempty"`
-	ExpiryDate       *time.Time `json:"expiry_date,omitempty"`
-	Status           string     `json:"status"`
-	DocumentURL      string     `json:"document_url"`
+	FacilityID       int64      `json:"facility_id,string" db:"facility_id"`
+	Name             string     `json:"name" db:"name"`
+	IssuingAuthority *string    `json:"issuing_authority" db:"issuing_authority"`
+	IssueDate        *time.Time `json:"issue_date,omitempty" db:"issue_date"`
+	ExpiryDate       *time.Time `json:"expiry_date,omitempty" db:"expiry_date"`
+	Status           *string    `json:"status" db:"status"`
+	DocumentURL      *string    `json:"document_url" db:"document_url"`
 }
+
+func (FacilityCertification) TableName() string { return "facility_certifications" }
+func (FacilityCertification) snowflakeKeyed()   {}
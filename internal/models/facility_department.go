@@ -2,10 +2,13 @@ package models
 
 type FacilityDepartment struct {
 	BaseModel
-	FacilityID    int64  `json:"facility_id"`
-	Name          string `json:"name"`
-	Description   string `json:"description"`
-	FloorNumber   string `json:"floor_number"`
-	HeadDoctorID  *int64 `json:"head_doctor_id,omitempty"`
-	ContactNumber string `json:"contact_number"`
+	FacilityID    int64   `json:"facility_id,string" db:"facility_id"`
+	Name          string  `json:"name" db:"name"`
+	Description   *string `json:"description" db:"description"`
+	FloorNumber   *string `json:"floor_number" db:"floor_number"`
+	HeadDoctorID  *int64  `json:"head_doctor_id,string,omitempty" db:"head_doctor_id"`
+	ContactNumber *string `json:"contact_number" db:"contact_number"`
 }
+
+func (FacilityDepartment) TableName() string { return "facility_departments" }
+func (FacilityDepartment) snowflakeKeyed()   {}
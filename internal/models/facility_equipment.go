@@ -4,13 +4,16 @@ import "time"
 
 type FacilityEquipment struct {
 	BaseModel
-	FacilityID          int64      `json:"facility_id"`
-	DepartmentID        *int64     `json:"department_id,omitempty"`
-	Name                string     `json:"name"`
-	Model               string     `json:"model"`
-	Manufacturer        string     `json:"manufacturer"`
-	PurchaseDate        *time.Time `json:"purchase_date,omitempty"`
-	LastMaintenanceDate *time.Time `json:"last_maintenance_date,omitempty"`
-	NextMaintenanceDate *time.Time `json:"next_maintenance_date,omitempty"`
-	Status              string     `json:"status"`
+	FacilityID          int64      `json:"facility_id,string" db:"facility_id"`
+	DepartmentID        *int64     `json:"department_id,string,omitempty" db:"department_id"`
+	Name                string     `json:"name" db:"name"`
+	Model               *string    `json:"model" db:"model"`
+	Manufacturer        *string    `json:"manufacturer" db:"manufacturer"`
+	PurchaseDate        *time.Time `json:"purchase_date,omitempty" db:"purchase_date"`
+	LastMaintenanceDate *time.Time `json:"last_maintenance_date,omitempty" db:"last_maintenance_date"`
+	NextMaintenanceDate *time.Time `json:"next_maintenance_date,omitempty" db:"next_maintenance_date"`
+	Status              *string    `json:"status" db:"status"`
 }
+
+func (FacilityEquipment) TableName() string { return "facility_equipment" }
+func (FacilityEquipment) snowflakeKeyed()   {}
@@ -3,9 +3,11 @@ package models
 import "time"
 
 type FacilityInsuranceProvider struct {
-	FacilityID          int64          `json:"facility_id"`
-	InsuranceProviderID int64          `json:"insurance_provider_id"`
-	CoverageDetails     map[string]any `json:"coverage_details"`
-	CreatedAt           time.Time      `json:"created_at"`
-	UpdatedAt           time.Time      `json:"updated_at"`
+	FacilityID          int64     `json:"facility_id,string" db:"facility_id"`
+	InsuranceProviderID int64     `json:"insurance_provider_id,string" db:"insurance_provider_id"`
+	CoverageDetails     JSONMap   `json:"coverage_details" db:"coverage_details"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
 }
+
+func (FacilityInsuranceProvider) TableName() string { return "facility_insurance_providers" }
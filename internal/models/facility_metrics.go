@@ -2,10 +2,45 @@ package models
 
 import "time"
 
+// FacilityMetricType is an operational metric facilities report over time.
+type FacilityMetricType string
+
+const (
+	BedOccupancy  FacilityMetricType = "bed_occupancy"   // Percentage of beds in use
+	ERWaitMinutes FacilityMetricType = "er_wait_minutes" // Expected wait in the emergency room
+	DailyPatients FacilityMetricType = "daily_patients"  // Patients seen so far that day
+)
+
+// FacilityMetricTypes lists the values of the facility_metric_type enum.
+var FacilityMetricTypes = []FacilityMetricType{BedOccupancy, ERWaitMinutes, DailyPatients}
+
+// Valid reports whether t is one of FacilityMetricTypes.
+func (t FacilityMetricType) Valid() bool {
+	for _, metricType := range FacilityMetricTypes {
+		if t == metricType {
+			return true
+		}
+	}
+	return false
+}
+
+// FacilityMetrics is a single sample of a facility metric.
 type FacilityMetrics struct {
-	FacilityID  int64     `json:"facility_id"`
-	MetricType  string    `json:"metric_type"`
-	MetricValue float64   `json:"metric_value"`
-	MeasuredAt  time.Time `json:"measured_at"`
-	CreatedAt   time.Time `json:"created_at"`
+	FacilityID  int64              `json:"facility_id,string" db:"facility_id"`
+	MetricType  FacilityMetricType `json:"metric_type" db:"metric_type"`
+	MetricValue float64            `json:"metric_value" db:"metric_value"`
+	MeasuredAt  time.Time          `json:"measured_at" db:"measured_at"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+}
+
+func (FacilityMetrics) TableName() string { return "facility_metrics" }
+
+// FacilityMetricBucket summarizes the samples of a metric measured within one time bucket.
+type FacilityMetricBucket struct {
+	MetricType FacilityMetricType `json:"metric_type" db:"metric_type"`
+	Start      time.Time          `json:"start" db:"bucket_start"`
+	Avg        float64            `json:"avg" db:"avg"`
+	Min        float64            `json:"min" db:"min"`
+	Max        float64            `json:"max" db:"max"`
+	Count      int64              `json:"count" db:"count"`
 }
@@ -3,12 +3,15 @@ package models
 import "time"
 
 type FacilityOperatingHours struct {
-	ID           int64      `json:"id"`
-	FacilityID   int64      `json:"facility_id"`
-	DepartmentID *int64     `json:"department_id,omitempty"`
-	DayOfWeek    int        `json:"day_of_week"`
-	StartTime    *time.Time `json:"start_time,omitempty"`
-	EndTime      *time.Time `json:"end_time,omitempty"`
-	IsClosed     bool       `json:"is_closed"`
-	CreatedAt    time.Time  `json:"created_at"`
+	ID           int64      `json:"id,string" db:"id"`
+	FacilityID   int64      `json:"facility_id,string" db:"facility_id"`
+	DepartmentID *int64     `json:"department_id,string,omitempty" db:"department_id"`
+	DayOfWeek    int        `json:"day_of_week" db:"day_of_week"`
+	StartTime    *time.Time `json:"start_time,omitempty" db:"start_time"`
+	EndTime      *time.Time `json:"end_time,omitempty" db:"end_time"`
+	IsClosed     bool       `json:"is_closed" db:"is_closed"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 }
+
+func (FacilityOperatingHours) TableName() string { return "facility_operating_hours" }
+func (FacilityOperatingHours) snowflakeKeyed()   {}
@@ -4,9 +4,12 @@ import "time"
 
 type FacilityPlan struct {
 	BaseModel
-	FacilityID int64      `json:"facility_id"`
-	PlanID     int64      `json:"plan_id"`
-	StartDate  time.Time  `json:"start_date"`
-	EndDate    *time.Time `json:"end_date,omitempty"`
-	IsActive   bool       `json:"is_active"`
+	FacilityID int64      `json:"facility_id,string" db:"facility_id"`
+	PlanID     int64      `json:"plan_id,string" db:"plan_id"`
+	StartDate  time.Time  `json:"start_date" db:"start_date"`
+	EndDate    *time.Time `json:"end_date,omitempty" db:"end_date"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
 }
+
+func (FacilityPlan) TableName() string { return "facility_plans" }
+func (FacilityPlan) snowflakeKeyed()   {}
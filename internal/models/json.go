@@ -0,0 +1,83 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONMap represents a JSONB object column decoded into a generic map.
+type JSONMap map[string]any
+
+// Value implements driver.Valuer so a JSONMap can be written to a JSONB column.
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner so a JSONB column can be read into a JSONMap.
+func (m *JSONMap) Scan(src any) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONMap", src)
+	}
+
+	// Decode numbers as json.Number so large (e.g. Snowflake) IDs keep their precision
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(m)
+}
+
+// Int64 reads an integer field, returning false when it is missing or not an integer.
+func (m JSONMap) Int64(key string) (int64, bool) {
+	switch v := m[key].(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		return int64(v), v == float64(int64(v))
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// StringList represents a JSONB array of strings.
+type StringList []string
+
+// Value implements driver.Valuer so a StringList can be written to a JSONB column.
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner so a JSONB array column can be read into a StringList.
+func (l *StringList) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", src)
+	}
+}
@@ -2,9 +2,12 @@ package models
 
 type Plan struct {
 	BaseModel
-	Name         string         `json:"name"`
-	MonthlyPrice float64        `json:"monthly_price"`
-	YearlyPrice  float64        `json:"yearly_price"`
-	Description  string         `json:"description"`
-	Features     map[string]any `json:"features"`
+	Name         string     `json:"name" db:"name"`
+	MonthlyPrice float64    `json:"monthly_price" db:"monthly_price"`
+	YearlyPrice  float64    `json:"yearly_price" db:"yearly_price"`
+	Description  string     `json:"description" db:"description"`
+	Features     StringList `json:"features" db:"features"`
 }
+
+func (Plan) TableName() string { return "plans" }
+func (Plan) snowflakeKeyed()   {}
@@ -1,10 +1,16 @@
 package models
 
+import "time"
+
 type Review struct {
 	BaseModel
-	EntityType string  `json:"entity_type"`
-	EntityID   int64   `json:"entity_id"`
-	UserID     *int64  `json:"user_id,omitempty"`
-	Rating     float64 `json:"rating"`
-	Comment    string  `json:"comment"`
+	EntityType string     `json:"entity_type" db:"entity_type"`
+	EntityID   int64      `json:"entity_id,string" db:"entity_id"`
+	UserID     *int64     `json:"user_id,string,omitempty" db:"user_id"`
+	Rating     *float64   `json:"rating" db:"rating"`
+	Comment    *string    `json:"comment" db:"comment"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
+
+func (Review) TableName() string { return "reviews" }
+func (Review) snowflakeKeyed()   {}
@@ -2,6 +2,15 @@ package models
 
 import "time"
 
+// UserRole is what a user may do. It is carried in their access tokens.
+type UserRole string
+
+const (
+	RoleUser  UserRole = "user"
+	RoleStaff UserRole = "staff"
+	RoleAdmin UserRole = "admin"
+)
+
 // User represents the user model
 type User struct {
 	BaseModel
@@ -11,6 +20,7 @@ type User struct {
 	Password      string     `json:"password" db:"password"`
 	EmailVerified *time.Time `json:"email_verified" db:"email_verified"`
 	Image         string     `json:"image" db:"image"`
+	Role          UserRole   `json:"role" db:"role"`
 }
 
 // Session represents a session for a user
@@ -24,6 +34,13 @@ type Session struct {
 // VerificationToken represents a token for verifying user actions
 type VerificationToken struct {
 	BaseModel
-	Expires time.Time `json:"expires" db:"expires"`
-	Token   string    `json:"token" db:"token"`
+	Identifier string    `json:"identifier" db:"identifier"`
+	Expires    time.Time `json:"expires" db:"expires"`
+	Token      string    `json:"token" db:"token"`
 }
+
+func (User) TableName() string { return "users" }
+
+func (Session) TableName() string { return "sessions" }
+
+func (VerificationToken) TableName() string { return "verification_tokens" }
@@ -1,59 +1,110 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"server/internal/models"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // AuditLogRepository defines read-only operations for the AuditLog model.
 type AuditLogRepository interface {
-	Find(id int64) (*models.AuditLog, error)
-	FindMany(filter map[string]interface{}) ([]models.AuditLog, error)
+	Find(ctx context.Context, id int64) (*models.AuditLog, error)
+	FindMany(ctx context.Context, filter map[string]interface{}) ([]models.AuditLog, error)
+	Query(ctx context.Context, q AuditLogQuery) ([]models.AuditLog, error)
+	FacilityHistory(ctx context.Context, facilityID int64, limit, offset int) ([]models.AuditLog, error)
+	LatestAsOf(ctx context.Context, table, key string, value int64, asOf time.Time) ([]models.AuditLog, error)
 }
 
-// auditLogRepository is an implementation of AuditLogRepository.
-type auditLogRepository struct {
-	db *sqlx.DB
+// AuditLogQuery narrows an audit log search. Zero-valued fields are ignored.
+type AuditLogQuery struct {
+	TableName string
+	RecordID  *int64 // Matched against the "id" key of old_data/new_data
+	Operation string
+	Field     string // Only entries where this column changed
+	ChangedBy *int64
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
 }
 
-// NewAuditLogRepository initializes a new AuditLogRepository.
-func NewAuditLogRepository(db *sqlx.DB) AuditLogRepository {
-	return &auditLogRepository{db: db}
+// facilityChildTables lists the audited tables whose rows belong to a facility through facility_id.
+var facilityChildTables = []string{
+	"facility_departments",
+	"facility_equipment",
+	"facility_operating_hours",
+	"facility_insurance_providers",
+	"facility_certifications",
+	"facility_plans",
 }
 
-// Find fetches an audit log entry by its ID.
-func (r *auditLogRepository) Find(id int64) (*models.AuditLog, error) {
-	start := time.Now() // Start time for metrics
-
-	var log models.AuditLog
-	query := `SELECT * FROM audit_log WHERE id = $1`
-	err := r.db.Get(&log, query, id)
-
-	// Track the metrics for the Find operation
-	trackMetrics("Find", "audit_log", start, err)
+const (
+	defaultAuditLogLimit = 100
+	// maxAuditLogLimit caps the page size, since every entry is diffed and serialized.
+	maxAuditLogLimit = 500
+)
 
-	if err != nil {
-		return nil, err
-	}
-	return &log, nil
+// auditLogRepository is an implementation of AuditLogRepository.
+type auditLogRepository struct {
+	*sqlRepository[models.AuditLog]
 }
 
-// FindMany fetches audit log entries based on the filter.
-func (r *auditLogRepository) FindMany(filter map[string]interface{}) ([]models.AuditLog, error) {
-	start := time.Now() // Start time for metrics
+// NewAuditLogRepository initializes a new AuditLogRepository.
+func NewAuditLogRepository(db DBTX) AuditLogRepository {
+	return &auditLogRepository{newSQLRepository[models.AuditLog](db)}
+}
 
+// Query fetches audit log entries matching the given query, newest first.
+func (r *auditLogRepository) Query(ctx context.Context, q AuditLogQuery) ([]models.AuditLog, error) {
 	whereClauses := []string{}
 	args := []interface{}{}
 	argIndex := 1
 
-	for key, value := range filter {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", key, argIndex))
-		args = append(args, value)
+	if q.TableName != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("table_name = $%d", argIndex))
+		args = append(args, q.TableName)
+		argIndex++
+	}
+	if q.RecordID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("COALESCE(new_data->>'id', old_data->>'id') = $%d", argIndex))
+		args = append(args, strconv.FormatInt(*q.RecordID, 10))
+		argIndex++
+	}
+	if q.Operation != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("operation = $%d", argIndex))
+		args = append(args, strings.ToUpper(q.Operation))
+		argIndex++
+	}
+	if q.Field != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("(old_data->$%d::text) IS DISTINCT FROM (new_data->$%d::text)", argIndex, argIndex))
+		args = append(args, q.Field)
+		argIndex++
+	}
+	if q.ChangedBy != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("changed_by = $%d", argIndex))
+		args = append(args, *q.ChangedBy)
+		argIndex++
+	}
+	if q.RequestID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("request_id = $%d", argIndex))
+		args = append(args, q.RequestID)
+		argIndex++
+	}
+	if q.From != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("changed_at >= $%d", argIndex))
+		args = append(args, *q.From)
+		argIndex++
+	}
+	if q.To != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("changed_at <= $%d", argIndex))
+		args = append(args, *q.To)
 		argIndex++
 	}
 
@@ -61,15 +112,43 @@ func (r *auditLogRepository) FindMany(filter map[string]interface{}) ([]models.A
 	if len(whereClauses) > 0 {
 		query += " WHERE " + strings.Join(whereClauses, " AND ")
 	}
+	query += fmt.Sprintf(" ORDER BY changed_at DESC, id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, auditLogLimit(q.Limit), q.Offset)
 
-	var logs []models.AuditLog
-	err := r.db.Select(&logs, query, args...)
+	return r.selectQuery(ctx, query, args...)
+}
 
-	// Track the metrics for the FindMany operation
-	trackMetrics("FindMany", "audit_log", start, err)
+// FacilityHistory fetches the audit trail of a facility and all of its dependent rows in chronological order.
+func (r *auditLogRepository) FacilityHistory(ctx context.Context, facilityID int64, limit, offset int) ([]models.AuditLog, error) {
+	query := `
+		SELECT * FROM audit_log
+		WHERE (table_name = 'facilities' AND COALESCE(new_data->>'id', old_data->>'id') = $1)
+		   OR (table_name = ANY($2) AND COALESCE(new_data->>'facility_id', old_data->>'facility_id') = $1)
+		ORDER BY changed_at ASC, id ASC
+		LIMIT $3 OFFSET $4`
+
+	return r.selectQuery(ctx, query,
+		strconv.FormatInt(facilityID, 10), pq.Array(facilityChildTables), auditLogLimit(limit), offset)
+}
+
+// LatestAsOf fetches, for every record of table whose key column equals value, the last audit entry
+// written at or before asOf. Entries whose operation is DELETE mark records that no longer existed.
+func (r *auditLogRepository) LatestAsOf(ctx context.Context, table, key string, value int64, asOf time.Time) ([]models.AuditLog, error) {
+	query := `
+		SELECT DISTINCT ON (COALESCE(new_data->>'id', old_data->>'id')) *
+		FROM audit_log
+		WHERE table_name = $1
+		  AND COALESCE(new_data->>$2::text, old_data->>$2::text) = $3
+		  AND changed_at <= $4
+		ORDER BY COALESCE(new_data->>'id', old_data->>'id'), changed_at DESC, id DESC`
+
+	return r.selectQuery(ctx, query, table, key, strconv.FormatInt(value, 10), asOf)
+}
 
-	if err != nil {
-		return nil, err
+// auditLogLimit applies the default page size to non-positive limits and caps the others.
+func auditLogLimit(limit int) int {
+	if limit <= 0 {
+		return defaultAuditLogLimit
 	}
-	return logs, nil
+	return min(limit, maxAuditLogLimit)
 }
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"unicode/utf8"
+
+	"server/internal/models"
+)
+
+// Actor identifies who performed a write and from which request.
+// log_audit() records these values alongside every audited row change.
+type Actor struct {
+	UserID    *int64
+	Role      models.UserRole // Role of the authenticated user, from their verified token; not audited
+	RequestID string
+	Reason    string // Optional description of why the change was made, e.g. a restore
+}
+
+// maxAuditRequestIDLength is the length of audit_log.request_id, VARCHAR(64).
+const maxAuditRequestIDLength = 64
+
+// SetAuditActor sets the transaction-local settings read by log_audit().
+// It must be called inside a transaction; outside one the settings are discarded immediately.
+// Request IDs longer than audit_log.request_id are truncated, so they cannot make audited writes fail.
+func SetAuditActor(ctx context.Context, tx DBTX, actor Actor) error {
+	if utf8.RuneCountInString(actor.RequestID) > maxAuditRequestIDLength {
+		actor.RequestID = string([]rune(actor.RequestID)[:maxAuditRequestIDLength])
+	}
+
+	userID := ""
+	if actor.UserID != nil {
+		userID = strconv.FormatInt(*actor.UserID, 10)
+	}
+
+	_, err := tx.ExecContext(ctx, `SELECT set_config('app.user_id', $1, true), set_config('app.request_id', $2, true), set_config('app.reason', $3, true)`,
+		userID, actor.RequestID, actor.Reason)
+	return err
+}
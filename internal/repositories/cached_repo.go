@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"server/internal/models"
+	"server/pkg/cache"
+	"server/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// invalidationTimeout bounds the Redis round trips of an invalidation. They run detached from the
+// request, so a request that times out right after committing still invalidates what it wrote.
+const invalidationTimeout = 2 * time.Second
+
+// cachedFacilityRepository serves Find from a cache and passes everything else to the repository.
+type cachedFacilityRepository struct {
+	FacilityRepository
+	cache *cache.Cache[models.Facility]
+}
+
+// NewCachedFacilityRepository caches the facilities found by ID through repo in c. Writes to
+// facilities made through any repository invalidate the entries of the rows they change.
+func NewCachedFacilityRepository(repo FacilityRepository, c *cache.Cache[models.Facility]) FacilityRepository {
+	invalidateOnWrite(models.Facility{}.TableName(), c)
+	return &cachedFacilityRepository{FacilityRepository: repo, cache: c}
+}
+
+func (r *cachedFacilityRepository) Find(ctx context.Context, id int64) (*models.Facility, error) {
+	return cachedFind(ctx, r.cache, id, r.FacilityRepository.Find)
+}
+
+// cachedCitiesRepository serves Find from a cache and passes everything else to the repository.
+type cachedCitiesRepository struct {
+	CitiesRepository
+	cache *cache.Cache[models.City]
+}
+
+// NewCachedCitiesRepository caches the cities found by ID through repo in c. Writes to cities made
+// through any repository invalidate the entries of the rows they change.
+func NewCachedCitiesRepository(repo CitiesRepository, c *cache.Cache[models.City]) CitiesRepository {
+	invalidateOnWrite(models.City{}.TableName(), c)
+	return &cachedCitiesRepository{CitiesRepository: repo, cache: c}
+}
+
+func (r *cachedCitiesRepository) Find(ctx context.Context, id int64) (*models.City, error) {
+	return cachedFind(ctx, r.cache, id, r.CitiesRepository.Find)
+}
+
+// cachedFind returns the entity with the given ID from c, calling find on a miss. Lookups that
+// include trashed rows bypass the cache, which only holds live ones. Errors, including
+// sql.ErrNoRows, are not cached. The entity returned shares pointer fields with the cached copy
+// and must not be modified through them.
+func cachedFind[T any](ctx context.Context, c *cache.Cache[T], id int64, find func(context.Context, int64) (*T, error)) (*T, error) {
+	if includesDeleted(ctx) {
+		return find(ctx, id)
+	}
+
+	entity, err := c.Get(ctx, strconv.FormatInt(id, 10), func(ctx context.Context) (T, error) {
+		entity, err := find(ctx, id)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return *entity, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// invalidateOnWrite drops the entries of the rows of table that are written from c.
+func invalidateOnWrite[T any](table string, c *cache.Cache[T]) {
+	OnWrite(table, func(ctx context.Context, ids []int64) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationTimeout)
+		defer cancel()
+
+		var err error
+		if ids == nil {
+			err = c.InvalidateAll(ctx)
+		} else {
+			keys := make([]string, len(ids))
+			for i, id := range ids {
+				keys[i] = strconv.FormatInt(id, 10)
+			}
+			err = c.Invalidate(ctx, keys...)
+		}
+		if err != nil {
+			// The local tier is already clean; what Redis still holds expires with the cache TTL
+			logger.FromContext(ctx).Error("Failed to invalidate cache", zap.String("table", table), zap.Error(err))
+		}
+	})
+}
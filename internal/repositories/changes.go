@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WriteListener is told that rows of a table changed. ids lists the changed rows; nil means any
+// row of the table may have changed.
+type WriteListener func(ctx context.Context, ids []int64)
+
+var writeListeners = struct {
+	sync.RWMutex
+	byTable map[string][]WriteListener
+}{byTable: map[string][]WriteListener{}}
+
+// OnWrite registers fn to be told about updates, deletes and restores of rows of table made through
+// any repository, including repositories of a UnitOfWork. Writes made in a transaction are reported
+// once it commits, and not at all when it rolls back. Inserts are not reported: no reader can have
+// seen a row before it exists.
+func OnWrite(table string, fn WriteListener) {
+	writeListeners.Lock()
+	defer writeListeners.Unlock()
+	writeListeners.byTable[table] = append(writeListeners.byTable[table], fn)
+}
+
+// activeTransactions maps the transactions run by a Transactor to their state, so writes made
+// through a repository bound to one can defer their notification until it commits.
+var activeTransactions sync.Map // *sqlx.Tx -> *txState
+
+// notifyWrite tells the listeners of table that the rows with ids were written through db.
+func notifyWrite(ctx context.Context, db DBTX, table string, ids []int64) {
+	writeListeners.RLock()
+	listeners := writeListeners.byTable[table]
+	writeListeners.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+
+	notify := func() {
+		for _, fn := range listeners {
+			fn(ctx, ids)
+		}
+	}
+	if tx, ok := db.(*sqlx.Tx); ok {
+		if state, ok := activeTransactions.Load(tx); ok {
+			state.(*txState).afterCommit = append(state.(*txState).afterCommit, notify)
+			return
+		}
+	}
+	notify()
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"server/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// FacilityMetricsRepository stores facility metric samples in the day-partitioned facility_metrics
+// table and manages its partitions.
+type FacilityMetricsRepository interface {
+	Insert(ctx context.Context, samples []models.FacilityMetrics) (int64, error)
+	Buckets(ctx context.Context, q FacilityMetricsQuery) ([]models.FacilityMetricBucket, error)
+	Latest(ctx context.Context, facilityID int64, since time.Time) ([]models.FacilityMetrics, error)
+	EnsurePartitions(ctx context.Context, from, to time.Time) error
+	DropPartitionsBefore(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// FacilityMetricsQuery selects the samples of a facility measured in [From, To) and summarizes
+// them in buckets of the given width, aligned to From.
+type FacilityMetricsQuery struct {
+	FacilityID int64
+	Type       models.FacilityMetricType // Empty matches every metric
+	From       time.Time
+	To         time.Time
+	Bucket     time.Duration
+}
+
+// facilityMetricsPartitionPrefix starts the name of every partition, which ends in its UTC day.
+const facilityMetricsPartitionPrefix = "facility_metrics_p"
+
+// facilityMetricsRepository is an implementation of FacilityMetricsRepository.
+type facilityMetricsRepository struct {
+	db DBTX
+}
+
+// NewFacilityMetricsRepository initializes a new FacilityMetricsRepository.
+func NewFacilityMetricsRepository(db DBTX) FacilityMetricsRepository {
+	return &facilityMetricsRepository{db: db}
+}
+
+// Insert stores the samples in a single statement and returns how many were written. A sample
+// whose facility, metric and time are already stored overwrites the stored value, so samples
+// must be unique by those within a call. The partitions of their days must exist.
+func (r *facilityMetricsRepository) Insert(ctx context.Context, samples []models.FacilityMetrics) (int64, error) {
+	if len(samples) == 0 {
+		return 0, nil
+	}
+
+	facilityIDs := make([]int64, len(samples))
+	metricTypes := make([]string, len(samples))
+	values := make([]float64, len(samples))
+	measuredAt := make([]string, len(samples))
+	for i, sample := range samples {
+		facilityIDs[i] = sample.FacilityID
+		metricTypes[i] = string(sample.MetricType)
+		values[i] = sample.MetricValue
+		measuredAt[i] = sample.MeasuredAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	query := `
+		INSERT INTO facility_metrics (facility_id, metric_type, metric_value, measured_at)
+		SELECT * FROM unnest($1::bigint[], $2::facility_metric_type[], $3::double precision[], $4::timestamptz[])
+		ON CONFLICT (facility_id, metric_type, measured_at) DO UPDATE SET metric_value = EXCLUDED.metric_value`
+
+	result, err := r.db.ExecContext(ctx, query,
+		pq.Array(facilityIDs), pq.Array(metricTypes), pq.Array(values), pq.Array(measuredAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Buckets returns the average, minimum and maximum of every bucket holding samples, ordered by
+// metric and bucket start. Buckets without samples are left out.
+func (r *facilityMetricsRepository) Buckets(ctx context.Context, q FacilityMetricsQuery) ([]models.FacilityMetricBucket, error) {
+	whereClauses := []string{"facility_id = $1", "measured_at >= $2", "measured_at < $3"}
+	args := []interface{}{q.FacilityID, q.From, q.To, q.Bucket.Seconds()}
+	if q.Type != "" {
+		whereClauses = append(whereClauses, "metric_type = $5")
+		args = append(args, string(q.Type))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT metric_type,
+		       date_bin(make_interval(secs => $4::double precision), measured_at, $2::timestamptz) AS bucket_start,
+		       AVG(metric_value) AS avg, MIN(metric_value) AS min, MAX(metric_value) AS max, COUNT(*) AS count
+		FROM facility_metrics
+		WHERE %s
+		GROUP BY metric_type, bucket_start
+		ORDER BY metric_type, bucket_start`, strings.Join(whereClauses, " AND "))
+
+	buckets := []models.FacilityMetricBucket{}
+	if err := r.db.SelectContext(ctx, &buckets, query, args...); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// Latest returns the most recent sample of every metric of the facility measured since the given time.
+func (r *facilityMetricsRepository) Latest(ctx context.Context, facilityID int64, since time.Time) ([]models.FacilityMetrics, error) {
+	query := `
+		SELECT DISTINCT ON (metric_type) *
+		FROM facility_metrics
+		WHERE facility_id = $1 AND measured_at >= $2
+		ORDER BY metric_type, measured_at DESC`
+
+	samples := []models.FacilityMetrics{}
+	if err := r.db.SelectContext(ctx, &samples, query, facilityID, since); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// EnsurePartitions creates the missing partitions of every UTC day from the day of from through
+// the day of to.
+func (r *facilityMetricsRepository) EnsurePartitions(ctx context.Context, from, to time.Time) error {
+	query := `
+		SELECT create_facility_metrics_partition(day::date)
+		FROM generate_series($1::date, $2::date, interval '1 day') AS day`
+
+	_, err := r.db.ExecContext(ctx, query, utcDay(from), utcDay(to))
+	return err
+}
+
+// DropPartitionsBefore drops the partitions of the UTC days that ended before the given time,
+// together with their samples, and returns their names.
+func (r *facilityMetricsRepository) DropPartitionsBefore(ctx context.Context, before time.Time) ([]string, error) {
+	// Partition names end in a zero-padded day, so they sort chronologically
+	query := `
+		SELECT child.relname
+		FROM pg_inherits
+		JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+		WHERE pg_inherits.inhparent = 'facility_metrics'::regclass
+		  AND starts_with(child.relname, $1)
+		  AND child.relname COLLATE "C" < $2
+		ORDER BY child.relname`
+
+	cutoff := facilityMetricsPartitionPrefix + strings.ReplaceAll(utcDay(before), "-", "")
+	var partitions []string
+	if err := r.db.SelectContext(ctx, &partitions, query, facilityMetricsPartitionPrefix, cutoff); err != nil {
+		return nil, err
+	}
+
+	dropped := []string{}
+	for _, partition := range partitions {
+		if _, err := r.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+pq.QuoteIdentifier(partition)); err != nil {
+			return dropped, fmt.Errorf("drop partition %s: %w", partition, err)
+		}
+		dropped = append(dropped, partition)
+	}
+	return dropped, nil
+}
+
+// utcDay formats the UTC day of t as YYYY-MM-DD.
+func utcDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
@@ -1,13 +1,64 @@
 package repositories
 
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
 // Repository defines the basic CRUD operations.
+// Every operation runs under ctx, so a cancelled request or an expired deadline aborts the query.
 type Repository[T any] interface {
-	Find(id int64) (*T, error)
-	FindMany(filter map[string]interface{}) ([]T, error)
-	Create(entity *T) (*T, error)
-	CreateMany(entities []T) ([]T, error)
-	Update(id int64, updates map[string]interface{}) (*T, error)
-	UpdateMany(filter map[string]interface{}, updates map[string]interface{}) (int64, error)
-	Delete(id int64) (*T, error)
-	DeleteMany(filter map[string]interface{}) ([]T, error)
+	Find(ctx context.Context, id int64) (*T, error)
+	FindMany(ctx context.Context, filter map[string]interface{}) ([]T, error)
+	Create(ctx context.Context, entity *T) (*T, error)
+	CreateMany(ctx context.Context, entities []T) ([]T, error)
+	Update(ctx context.Context, id int64, updates map[string]interface{}) (*T, error)
+	// CompareAndUpdate applies updates only if the row's updated_at still equals version, returning
+	// ErrVersionConflict when another write got there first. It is the basis of optimistic locking.
+	CompareAndUpdate(ctx context.Context, id int64, version time.Time, updates map[string]interface{}) (*T, error)
+	UpdateMany(ctx context.Context, filter map[string]interface{}, updates map[string]interface{}) (int64, error)
+	Delete(ctx context.Context, id int64) (*T, error)
+	DeleteMany(ctx context.Context, filter map[string]interface{}) ([]T, error)
+}
+
+// DBTX is the query surface shared by *sqlx.DB and *sqlx.Tx, so repositories can run
+// either directly against the pool or inside a caller-owned transaction.
+// Named queries go through sqlx.NamedQueryContext, which accepts either.
+type DBTX interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// txHandle is a transaction that a repository method can commit or roll back.
+type txHandle interface {
+	DBTX
+	Commit() error
+	Rollback() error
 }
+
+// beginx starts a transaction on db. When db is already a transaction the repository joins it
+// instead, leaving commit and rollback to the transaction's owner.
+func beginx(ctx context.Context, db DBTX) (txHandle, error) {
+	switch d := db.(type) {
+	case *sqlx.DB:
+		return d.BeginTxx(ctx, nil)
+	case *sqlx.Tx:
+		return joinedTx{d}, nil
+	default:
+		return nil, fmt.Errorf("cannot begin a transaction on %T", db)
+	}
+}
+
+// joinedTx wraps a caller-owned transaction whose lifecycle the repository must not end.
+type joinedTx struct {
+	*sqlx.Tx
+}
+
+func (joinedTx) Commit() error   { return nil }
+func (joinedTx) Rollback() error { return nil }
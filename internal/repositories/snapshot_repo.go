@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"server/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// SnapshotRepository writes audit log row snapshots back into their tables.
+// It is used by point-in-time restores and is expected to run inside an audited transaction.
+type SnapshotRepository interface {
+	Lock(ctx context.Context, table string, id int64) error
+	Upsert(ctx context.Context, table string, row models.JSONMap) error
+	DeleteExcept(ctx context.Context, table, key string, value int64, keepIDs []int64) (int64, error)
+}
+
+// restorableTables lists the tables whose rows may be restored from audit snapshots.
+var restorableTables = map[string]bool{
+	"facilities":               true,
+	"facility_departments":     true,
+	"facility_operating_hours": true,
+	"facility_equipment":       true,
+}
+
+// snapshotKeptColumns are the columns of an existing row that Upsert does not overwrite.
+var snapshotKeptColumns = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// snapshotRepository is an implementation of SnapshotRepository.
+type snapshotRepository struct {
+	db DBTX
+}
+
+// NewSnapshotRepository initializes a new SnapshotRepository.
+func NewSnapshotRepository(db DBTX) SnapshotRepository {
+	return &snapshotRepository{db: db}
+}
+
+// Lock locks the row of table with the given ID until the transaction ends, so concurrent writes
+// to it wait for the restore. A missing row is not an error; there is nothing to lock.
+func (r *snapshotRepository) Lock(ctx context.Context, table string, id int64) error {
+	if !restorableTables[table] {
+		return fmt.Errorf("table %q cannot be restored", table)
+	}
+
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE`, pq.QuoteIdentifier(table))
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Upsert inserts the snapshot row, or overwrites the columns present in the snapshot when a row with
+// the same ID exists. jsonb_populate_record converts the JSON values back into the column types.
+// An overwritten row keeps its created_at, and its updated_at advances like on any other write, so
+// its version never goes backwards and ETags handed out earlier stop matching. Its deleted_at is
+// taken from the snapshot, so restoring a trashed row to before its deletion takes it out of the trash.
+func (r *snapshotRepository) Upsert(ctx context.Context, table string, row models.JSONMap) error {
+	if !restorableTables[table] {
+		return fmt.Errorf("table %q cannot be restored", table)
+	}
+
+	setClauses := []string{}
+	for _, column := range sortedKeys(row) {
+		if snapshotKeptColumns[column] {
+			continue
+		}
+		quoted := pq.QuoteIdentifier(column)
+		setClauses = append(setClauses, fmt.Sprintf("%s = EXCLUDED.%s", quoted, quoted))
+	}
+	setClauses = append(setClauses, bumpUpdatedAt)
+
+	quotedTable := pq.QuoteIdentifier(table)
+	query := fmt.Sprintf(`
+		INSERT INTO %s
+		SELECT * FROM jsonb_populate_record(NULL::%s, $1)
+		ON CONFLICT (id) DO UPDATE SET %s`, quotedTable, quotedTable, strings.Join(setClauses, ", "))
+
+	_, err := r.db.ExecContext(ctx, query, row)
+	if err == nil {
+		// The row's ID is buried in the JSON; restores are rare enough to report the whole table
+		notifyWrite(ctx, r.db, table, nil)
+	}
+	return err
+}
+
+// DeleteExcept deletes the rows of table whose key column equals value and whose ID is not in keepIDs.
+func (r *snapshotRepository) DeleteExcept(ctx context.Context, table, key string, value int64, keepIDs []int64) (int64, error) {
+	if !restorableTables[table] {
+		return 0, fmt.Errorf("table %q cannot be restored", table)
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND NOT (id = ANY($2))`,
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(key))
+
+	result, err := r.db.ExecContext(ctx, query, value, pq.Array(keepIDs))
+	if err != nil {
+		return 0, err
+	}
+	notifyWrite(ctx, r.db, table, nil)
+	return result.RowsAffected()
+}
+
+// sortedKeys returns the keys of row in a stable order so generated SQL is deterministic.
+func sortedKeys(row models.JSONMap) []string {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrNotSoftDeletable is returned by trash operations on tables without a deleted_at column.
+var ErrNotSoftDeletable = errors.New("table has no deleted_at column")
+
+// deletedAtColumn marks a table as soft-deletable: Delete stamps it instead of removing the row,
+// and rows where it is set are left out of queries unless the context asks for them.
+const deletedAtColumn = "deleted_at"
+
+// SoftDeleteRepository is a Repository over a table with a deleted_at column. Delete and DeleteMany
+// move rows to the trash, and reads and updates skip trashed rows unless ctx comes from WithDeleted.
+type SoftDeleteRepository[T any] interface {
+	Repository[T]
+	// FindDeleted fetches the trashed rows matching filter, most recently deleted first.
+	FindDeleted(ctx context.Context, filter map[string]interface{}) ([]T, error)
+	// Restore takes the row with the given ID out of the trash, returning sql.ErrNoRows when it is not trashed.
+	Restore(ctx context.Context, id int64) (*T, error)
+	// Purge hard-deletes rows trashed before the given time and returns how many were removed.
+	// Rows still referenced by a foreign key are kept until the referencing rows are gone.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// withDeletedKey is the context key set by WithDeleted.
+type withDeletedKey struct{}
+
+// WithDeleted returns a context under which repositories also read and update soft-deleted rows.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withDeletedKey{}, true)
+}
+
+// includesDeleted reports whether ctx comes from WithDeleted.
+func includesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(withDeletedKey{}).(bool)
+	return include
+}
+
+// softDeletes reports whether the table of T keeps deleted rows in the trash.
+func (r *sqlRepository[T]) softDeletes() bool {
+	return r.meta.known[deletedAtColumn]
+}
+
+// scoped appends the condition hiding trashed rows to where, unless ctx includes them.
+func (r *sqlRepository[T]) scoped(ctx context.Context, where string) string {
+	if !r.softDeletes() || includesDeleted(ctx) {
+		return where
+	}
+	if where == "" {
+		return deletedAtColumn + " IS NULL"
+	}
+	return where + " AND " + deletedAtColumn + " IS NULL"
+}
+
+// trashSet is the assignment that moves a row to the trash.
+func (r *sqlRepository[T]) trashSet() string {
+	set := deletedAtColumn + " = now()"
+	if r.meta.known["updated_at"] {
+		set += ", " + bumpUpdatedAt
+	}
+	return set
+}
+
+// FindDeleted fetches the trashed rows matching filter, most recently deleted first.
+func (r *sqlRepository[T]) FindDeleted(ctx context.Context, filter map[string]interface{}) ([]T, error) {
+	if !r.softDeletes() {
+		return nil, fmt.Errorf("%w: %s", ErrNotSoftDeletable, r.meta.name)
+	}
+	where, args, err := r.whereClause(filter, 1)
+	if err != nil {
+		return nil, err
+	}
+	if where != "" {
+		where += " AND "
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s%s IS NOT NULL ORDER BY %s DESC`,
+		r.returning, r.meta.name, where, deletedAtColumn, deletedAtColumn)
+	return r.selectQuery(ctx, query, args...)
+}
+
+// Restore takes the row with the given ID out of the trash and returns it.
+func (r *sqlRepository[T]) Restore(ctx context.Context, id int64) (*T, error) {
+	if !r.softDeletes() {
+		return nil, fmt.Errorf("%w: %s", ErrNotSoftDeletable, r.meta.name)
+	}
+	set := deletedAtColumn + " = NULL"
+	if r.meta.known["updated_at"] {
+		set += ", " + bumpUpdatedAt
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $1 AND %s IS NOT NULL RETURNING %s`,
+		r.meta.name, set, deletedAtColumn, r.returning)
+	entity, err := r.getQuery(ctx, query, id)
+	if err == nil {
+		r.written(ctx, []int64{id})
+	}
+	return entity, err
+}
+
+// Purge hard-deletes the rows trashed before the given time, one statement per row so a row that
+// is still referenced only skips that row. It must run outside a transaction: a failed statement
+// would abort it.
+func (r *sqlRepository[T]) Purge(ctx context.Context, before time.Time) (int64, error) {
+	if !r.softDeletes() {
+		return 0, fmt.Errorf("%w: %s", ErrNotSoftDeletable, r.meta.name)
+	}
+
+	var ids []int64
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE %s < $1 ORDER BY id`, r.meta.name, deletedAtColumn)
+	if err := r.db.SelectContext(ctx, &ids, query, before); err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	deleteQuery := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND %s < $2`, r.meta.name, deletedAtColumn)
+	for _, id := range ids {
+		result, err := r.db.ExecContext(ctx, deleteQuery, id, before)
+		if isForeignKeyViolation(err) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += n
+	}
+	return purged, nil
+}
+
+// isForeignKeyViolation reports whether err is PostgreSQL refusing to delete a row that is still referenced.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503" // foreign_key_violation
+}
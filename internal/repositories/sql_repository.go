@@ -0,0 +1,403 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"server/internal/models"
+	"server/pkg/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrUnknownColumn is returned when a filter or update refers to a column the model does not map.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrEmptyFilter is returned by bulk operations called without a filter.
+	ErrEmptyFilter = errors.New("a filter is required")
+	// ErrNoUpdates is returned when an update has no columns to set.
+	ErrNoUpdates = errors.New("no columns to update")
+	// ErrVersionConflict is returned by CompareAndUpdate when the row changed after it was read.
+	ErrVersionConflict = errors.New("row was modified concurrently")
+	// ErrNotVersioned is returned by CompareAndUpdate on tables without an updated_at column.
+	ErrNotVersioned = errors.New("table has no updated_at column")
+)
+
+// bumpUpdatedAt advances updated_at on every write, even for writes within one transaction or one
+// clock tick, so each row version is distinct and can serve as an optimistic locking token.
+const bumpUpdatedAt = "updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')"
+
+// generatedColumns are filled in by the database and never written by Create, Update or
+// CompareAndUpdate. deleted_at is only ever set by Delete and cleared by Restore, so rows enter and
+// leave the trash through them, and updated_at only ever advances through bumpUpdatedAt.
+var generatedColumns = map[string]bool{"id": true, "created_at": true, "updated_at": true, deletedAtColumn: true}
+
+// tableMeta describes how a model maps onto its table.
+type tableMeta struct {
+	name    string
+	columns []string // Every db-tagged column, in struct order
+	insert  []string // Columns written by Create
+	known   map[string]bool
+	// snowflake is set for tables without an ID default, whose IDs Create assigns
+	snowflake bool
+}
+
+// tableMetas caches tableMeta by model type; repositories are created per transaction.
+var tableMetas sync.Map
+
+// newTableMeta derives the table metadata of T from its TableName method and db tags.
+func newTableMeta[T models.Tabler]() tableMeta {
+	var entity T
+	if meta, ok := tableMetas.Load(reflect.TypeOf(entity)); ok {
+		return meta.(tableMeta)
+	}
+
+	_, snowflake := any(entity).(models.SnowflakeKeyed)
+	meta := tableMeta{name: entity.TableName(), known: map[string]bool{}, snowflake: snowflake}
+	for _, column := range dbColumns(reflect.TypeOf(entity)) {
+		meta.columns = append(meta.columns, column)
+		meta.known[column] = true
+		if !generatedColumns[column] || (column == "id" && snowflake) {
+			meta.insert = append(meta.insert, column)
+		}
+	}
+
+	tableMetas.Store(reflect.TypeOf(entity), meta)
+	return meta
+}
+
+// dbColumns returns the db tags of t, flattening embedded structs the same way sqlx does.
+func dbColumns(t reflect.Type) []string {
+	columns := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("db"), ",")[0]
+		switch {
+		case !f.IsExported() || tag == "-":
+		case f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct:
+			columns = append(columns, dbColumns(f.Type)...)
+		case tag != "":
+			columns = append(columns, tag)
+		}
+	}
+	return columns
+}
+
+// sqlRepository implements Repository[T] for any model that names its table and tags its columns.
+// Entity repositories embed it and add their own queries through selectQuery and getQuery.
+type sqlRepository[T models.Tabler] struct {
+	db   DBTX
+	meta tableMeta
+
+	insertQuery string
+	returning   string
+}
+
+// newSQLRepository initializes a sqlRepository for T.
+func newSQLRepository[T models.Tabler](db DBTX) *sqlRepository[T] {
+	meta := newTableMeta[T]()
+
+	placeholders := make([]string, len(meta.insert))
+	for i, column := range meta.insert {
+		placeholders[i] = ":" + column
+	}
+	returning := strings.Join(meta.columns, ", ")
+
+	return &sqlRepository[T]{
+		db:        db,
+		meta:      meta,
+		returning: returning,
+		insertQuery: fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING %s`,
+			meta.name, strings.Join(meta.insert, ", "), strings.Join(placeholders, ", "), returning),
+	}
+}
+
+// Find fetches a row by its ID.
+func (r *sqlRepository[T]) Find(ctx context.Context, id int64) (*T, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s`, r.returning, r.meta.name, r.scoped(ctx, "id = $1"))
+	return r.getQuery(ctx, query, id)
+}
+
+// FindMany fetches the rows whose columns equal every value in filter, ordered by ID when the table has one.
+func (r *sqlRepository[T]) FindMany(ctx context.Context, filter map[string]interface{}) ([]T, error) {
+	where, args, err := r.whereClause(filter, 1)
+	if err != nil {
+		return nil, err
+	}
+	where = r.scoped(ctx, where)
+
+	query := fmt.Sprintf(`SELECT %s FROM %s`, r.returning, r.meta.name)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if r.meta.known["id"] {
+		query += " ORDER BY id"
+	}
+
+	return r.selectQuery(ctx, query, args...)
+}
+
+// Create inserts entity and fills it with the stored row.
+func (r *sqlRepository[T]) Create(ctx context.Context, entity *T) (*T, error) {
+	if err := r.insert(ctx, r.db, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// CreateMany inserts every entity in a single transaction, so either all rows are stored or none.
+func (r *sqlRepository[T]) CreateMany(ctx context.Context, entities []T) ([]T, error) {
+	results := make([]T, 0, len(entities))
+	if len(entities) == 0 {
+		return results, nil
+	}
+
+	tx, err := beginx(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, entity := range entities {
+		if err := r.insert(ctx, tx, &entity); err != nil {
+			return nil, err
+		}
+		results = append(results, entity)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// insert runs the insert query on db and scans the returned row back into entity.
+// Entities of Snowflake-keyed tables get a new ID unless they already have one.
+func (r *sqlRepository[T]) insert(ctx context.Context, db DBTX, entity *T) error {
+	if r.meta.snowflake {
+		if id := reflect.ValueOf(entity).Elem().FieldByName("ID"); id.Int() == 0 {
+			next, err := utils.GenerateSnowflakeID()
+			if err != nil {
+				return fmt.Errorf("failed to generate ID: %w", err)
+			}
+			id.SetInt(next)
+		}
+	}
+
+	rows, err := sqlx.NamedQueryContext(ctx, db, r.insertQuery, entity)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.StructScan(entity); err != nil {
+		return fmt.Errorf("failed to scan row: %w", err)
+	}
+	return rows.Close()
+}
+
+// Update sets the given columns of the row with the given ID and returns the updated row.
+func (r *sqlRepository[T]) Update(ctx context.Context, id int64, updates map[string]interface{}) (*T, error) {
+	set, args, err := r.setClause(updates)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, id)
+
+	where := r.scoped(ctx, fmt.Sprintf("id = $%d", len(args)))
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s RETURNING %s`, r.meta.name, set, where, r.returning)
+	entity, err := r.getQuery(ctx, query, args...)
+	if err == nil {
+		r.written(ctx, []int64{id})
+	}
+	return entity, err
+}
+
+// CompareAndUpdate sets the given columns of the row with the given ID if its updated_at still equals
+// version. It returns sql.ErrNoRows when the row does not exist and ErrVersionConflict when it has
+// been modified since version was read.
+func (r *sqlRepository[T]) CompareAndUpdate(ctx context.Context, id int64, version time.Time, updates map[string]interface{}) (*T, error) {
+	if !r.meta.known["updated_at"] {
+		return nil, fmt.Errorf("%w: %s", ErrNotVersioned, r.meta.name)
+	}
+
+	set, args, err := r.setClause(updates)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, id, version)
+
+	where := r.scoped(ctx, fmt.Sprintf("id = $%d AND updated_at = $%d", len(args)-1, len(args)))
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s RETURNING %s`, r.meta.name, set, where, r.returning)
+	entity, err := r.getQuery(ctx, query, args...)
+	if err == nil {
+		r.written(ctx, []int64{id})
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return entity, err
+	}
+
+	// Nothing matched: tell a missing row apart from a stale version
+	var exists bool
+	existsQuery := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE %s)`, r.meta.name, r.scoped(ctx, "id = $1"))
+	if err := r.db.GetContext(ctx, &exists, existsQuery, id); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrVersionConflict
+	}
+	return nil, sql.ErrNoRows
+}
+
+// UpdateMany sets the given columns of every row matching filter and returns the number of rows changed.
+func (r *sqlRepository[T]) UpdateMany(ctx context.Context, filter map[string]interface{}, updates map[string]interface{}) (int64, error) {
+	set, args, err := r.setClause(updates)
+	if err != nil {
+		return 0, err
+	}
+	if len(filter) == 0 {
+		return 0, ErrEmptyFilter
+	}
+	where, whereArgs, err := r.whereClause(filter, len(args)+1)
+	if err != nil {
+		return 0, err
+	}
+	args = append(args, whereArgs...)
+	where = r.scoped(ctx, where)
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, r.meta.name, set, where)
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	r.written(ctx, nil)
+	return result.RowsAffected()
+}
+
+// Delete removes the row with the given ID and returns it.
+// On soft-deletable tables the row is moved to the trash instead; trashed rows are not deleted again.
+func (r *sqlRepository[T]) Delete(ctx context.Context, id int64) (*T, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 RETURNING %s`, r.meta.name, r.returning)
+	if r.softDeletes() {
+		query = fmt.Sprintf(`UPDATE %s SET %s WHERE id = $1 AND %s IS NULL RETURNING %s`,
+			r.meta.name, r.trashSet(), deletedAtColumn, r.returning)
+	}
+	entity, err := r.getQuery(ctx, query, id)
+	if err == nil {
+		r.written(ctx, []int64{id})
+	}
+	return entity, err
+}
+
+// DeleteMany removes every row matching filter and returns the deleted rows.
+// On soft-deletable tables the rows are moved to the trash instead.
+func (r *sqlRepository[T]) DeleteMany(ctx context.Context, filter map[string]interface{}) ([]T, error) {
+	if len(filter) == 0 {
+		return nil, ErrEmptyFilter
+	}
+	where, args, err := r.whereClause(filter, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s RETURNING %s`, r.meta.name, where, r.returning)
+	if r.softDeletes() {
+		query = fmt.Sprintf(`UPDATE %s SET %s WHERE %s AND %s IS NULL RETURNING %s`,
+			r.meta.name, r.trashSet(), where, deletedAtColumn, r.returning)
+	}
+	entities, err := r.selectQuery(ctx, query, args...)
+	if err == nil && len(entities) > 0 {
+		ids := make([]int64, len(entities))
+		for i := range entities {
+			ids[i] = reflect.ValueOf(&entities[i]).Elem().FieldByName("ID").Int()
+		}
+		r.written(ctx, ids)
+	}
+	return entities, err
+}
+
+// written tells the write listeners of the table that the rows with ids changed; nil ids means
+// any row may have.
+func (r *sqlRepository[T]) written(ctx context.Context, ids []int64) {
+	notifyWrite(ctx, r.db, r.meta.name, ids)
+}
+
+// getQuery runs a query returning a single row of T.
+func (r *sqlRepository[T]) getQuery(ctx context.Context, query string, args ...interface{}) (*T, error) {
+	var entity T
+	err := r.db.GetContext(ctx, &entity, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// selectQuery runs a query returning rows of T.
+func (r *sqlRepository[T]) selectQuery(ctx context.Context, query string, args ...interface{}) ([]T, error) {
+	entities := []T{}
+	err := r.db.SelectContext(ctx, &entities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// setClause builds "col = $n" assignments for updates, numbering placeholders from 1, and bumps
+// updated_at. Generated columns cannot be updated.
+func (r *sqlRepository[T]) setClause(updates map[string]interface{}) (string, []interface{}, error) {
+	if len(updates) == 0 {
+		return "", nil, ErrNoUpdates
+	}
+
+	clauses := []string{}
+	args := []interface{}{}
+	for _, column := range sortedColumns(updates) {
+		if !r.meta.known[column] || generatedColumns[column] {
+			return "", nil, fmt.Errorf("%w %q on %s", ErrUnknownColumn, column, r.meta.name)
+		}
+		args = append(args, updates[column])
+		clauses = append(clauses, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if r.meta.known["updated_at"] {
+		clauses = append(clauses, bumpUpdatedAt)
+	}
+
+	return strings.Join(clauses, ", "), args, nil
+}
+
+// whereClause builds "col = $n" conditions for filter, numbering placeholders from first.
+func (r *sqlRepository[T]) whereClause(filter map[string]interface{}, first int) (string, []interface{}, error) {
+	clauses := []string{}
+	args := []interface{}{}
+	for _, column := range sortedColumns(filter) {
+		if !r.meta.known[column] {
+			return "", nil, fmt.Errorf("%w %q on %s", ErrUnknownColumn, column, r.meta.name)
+		}
+		args = append(args, filter[column])
+		clauses = append(clauses, fmt.Sprintf("%s = $%d", column, first+len(args)-1))
+	}
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// sortedColumns returns the keys of values in a stable order so generated SQL is deterministic.
+func sortedColumns(values map[string]interface{}) []string {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
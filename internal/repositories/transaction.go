@@ -0,0 +1,294 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// defaultTxAttempts is how often a transaction runs before a serialization failure is returned.
+	defaultTxAttempts = 3
+	// txRetryBaseDelay is the backoff before the first retry; it doubles on every further attempt.
+	txRetryBaseDelay = 10 * time.Millisecond
+)
+
+// TxFunc is the body of a transaction. ctx carries the transaction, so transactions started with
+// it from inside fn nest as savepoints, and uow hands out repositories bound to the transaction.
+type TxFunc func(ctx context.Context, uow *UnitOfWork) error
+
+// TxOptions configures a transaction started by a Transactor.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// Actor, when set, attributes every audited change made in the transaction.
+	Actor *Actor
+	// MaxAttempts bounds how often the transaction runs when PostgreSQL aborts it with a
+	// serialization failure or deadlock. Zero uses defaultTxAttempts.
+	MaxAttempts int
+}
+
+// Transactor runs work spanning several repositories atomically.
+type Transactor interface {
+	// WithinTransaction runs fn in a transaction that commits when fn returns nil and rolls back
+	// otherwise. Serialization failures and deadlocks re-run fn from the start, so fn must not
+	// have side effects outside the database.
+	//
+	// When ctx already carries a transaction, fn runs inside a savepoint of it instead: an error
+	// rolls back only fn's changes, isolation and retries are left to the outer transaction.
+	WithinTransaction(ctx context.Context, opts TxOptions, fn TxFunc) error
+
+	// WithinAudit runs fn in a transaction stamped with the actor.
+	// Repositories built on tx inside fn have their writes attributed to the actor.
+	WithinAudit(ctx context.Context, actor Actor, fn func(tx DBTX) error) error
+}
+
+// txContextKey is the context key of the active transaction.
+type txContextKey struct{}
+
+// txState is an active transaction, the number of savepoints created in it, the actor its writes
+// are attributed to and the hooks to run once it commits. A transaction, and therefore its state,
+// must not be shared between goroutines.
+type txState struct {
+	tx          *sqlx.Tx
+	savepoints  int
+	actor       Actor
+	afterCommit []func()
+}
+
+// transactor is an implementation of Transactor.
+type transactor struct {
+	db *sqlx.DB
+}
+
+// NewTransactor initializes a new Transactor.
+func NewTransactor(db *sqlx.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction runs fn in a transaction, or in a savepoint when ctx already carries one.
+func (t *transactor) WithinTransaction(ctx context.Context, opts TxOptions, fn TxFunc) error {
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return t.withinSavepoint(ctx, state, opts, fn)
+	}
+
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultTxAttempts
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = t.run(ctx, opts, fn)
+		if err == nil {
+			transactionTotal.WithLabelValues("committed").Inc()
+			return nil
+		}
+		if !isSerializationFailure(err) || attempt == attempts {
+			break
+		}
+
+		transactionTotal.WithLabelValues("retried").Inc()
+		if err := sleepBackoff(ctx, attempt); err != nil {
+			return err
+		}
+	}
+
+	transactionTotal.WithLabelValues("rolled_back").Inc()
+	return err
+}
+
+// WithinAudit runs fn inside a transaction stamped with the actor.
+func (t *transactor) WithinAudit(ctx context.Context, actor Actor, fn func(tx DBTX) error) error {
+	return t.WithinTransaction(ctx, TxOptions{Actor: &actor}, func(ctx context.Context, uow *UnitOfWork) error {
+		return fn(uow.DB())
+	})
+}
+
+// run executes a single attempt of a top-level transaction.
+func (t *transactor) run(ctx context.Context, opts TxOptions, fn TxFunc) error {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	state := &txState{tx: tx}
+	if opts.Actor != nil {
+		if err := SetAuditActor(ctx, tx, *opts.Actor); err != nil {
+			return err
+		}
+		state.actor = *opts.Actor
+	}
+
+	activeTransactions.Store(tx, state)
+	defer activeTransactions.Delete(tx)
+
+	txCtx := context.WithValue(ctx, txContextKey{}, state)
+	if err := fn(txCtx, &UnitOfWork{db: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// withinSavepoint runs fn inside a savepoint of the active transaction. An actor given for the
+// savepoint attributes only fn's writes; the outer actor is back in effect once it returns.
+func (t *transactor) withinSavepoint(ctx context.Context, state *txState, opts TxOptions, fn TxFunc) error {
+	state.savepoints++
+	name := fmt.Sprintf("sp_%d", state.savepoints)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	outer := state.actor
+	defer func() { state.actor = outer }()
+
+	err := func() error {
+		if opts.Actor != nil {
+			if err := SetAuditActor(ctx, state.tx, *opts.Actor); err != nil {
+				return err
+			}
+			state.actor = *opts.Actor
+		}
+		return fn(ctx, &UnitOfWork{db: state.tx})
+	}()
+	if err != nil {
+		// A failed statement aborts the transaction until the savepoint is rolled back, which
+		// also undoes the savepoint's actor
+		if _, rollbackErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return err
+	}
+	// Settings made in a released savepoint stay in effect for the rest of the transaction
+	if opts.Actor != nil {
+		return SetAuditActor(ctx, state.tx, outer)
+	}
+	return nil
+}
+
+// isSerializationFailure reports whether PostgreSQL aborted the transaction because it conflicted
+// with a concurrent one, in which case running it again may succeed.
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01" // serialization_failure, deadlock_detected
+}
+
+// sleepBackoff waits an exponentially growing, jittered delay before the next attempt.
+func sleepBackoff(ctx context.Context, attempt int) error {
+	delay := txRetryBaseDelay << (attempt - 1)
+	delay += time.Duration(rand.Int63n(int64(delay)))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// UnitOfWork hands out repositories bound to a single transaction.
+// Queries made through them are tracked by the repository metrics like any other query.
+type UnitOfWork struct {
+	db DBTX
+}
+
+// NewUnitOfWork hands out repositories bound to db, for work that needs no transaction of its own.
+func NewUnitOfWork(db DBTX) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// DB returns the transaction for queries no repository covers.
+func (u *UnitOfWork) DB() DBTX { return u.db }
+
+func (u *UnitOfWork) Auth() AuthRepository {
+	return NewAuthRepository(u.db)
+}
+
+func (u *UnitOfWork) AuditLog() AuditLogRepository {
+	return NewAuditLogRepository(u.db)
+}
+
+func (u *UnitOfWork) Cities() CitiesRepository {
+	return NewCitiesRepository(u.db)
+}
+
+func (u *UnitOfWork) Doctors() DoctorRepository {
+	return NewDoctorRepository(u.db)
+}
+
+func (u *UnitOfWork) Facilities() FacilityRepository {
+	return NewFacilityRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityAppointments() FacilityAppointmentRepository {
+	return NewFacilityAppointmentRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityCategories() FacilityCategoriesRepository {
+	return NewFacilityCategoriesRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityCertifications() FacilityCertificationsRepository {
+	return NewFacilityCertificationsRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityDepartments() FacilityDepartmentRepository {
+	return NewFacilityDepartmentRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityEquipment() FacilityEquipmentRepository {
+	return NewFacilityEquipmentRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityInsuranceProviders() FacilityInsuranceProvidersRepository {
+	return NewFacilityInsuranceProvidersRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityMetrics() FacilityMetricsRepository {
+	return NewFacilityMetricsRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityOperatingHours() FacilityOperatingHoursRepository {
+	return NewFacilityOperatingHoursRepository(u.db)
+}
+
+func (u *UnitOfWork) FacilityPlans() FacilityPlansRepository {
+	return NewFacilityPlansRepository(u.db)
+}
+
+func (u *UnitOfWork) Plans() PlansRepository {
+	return NewPlansRepository(u.db)
+}
+
+func (u *UnitOfWork) Reviews() ReviewsRepository {
+	return NewReviewsRepository(u.db)
+}
+
+func (u *UnitOfWork) Snapshots() SnapshotRepository {
+	return NewSnapshotRepository(u.db)
+}
@@ -0,0 +1,239 @@
+// Package schemacheck detects drift between the database schema and the Go models.
+//
+// It introspects information_schema on a migrated database and compares every table with the
+// `db`-tagged fields of its model, reporting missing and extra columns, incompatible types and
+// nullability mismatches that would otherwise only surface as StructScan failures at runtime.
+package schemacheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"server/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Kind classifies a drift finding.
+type Kind string
+
+const (
+	MissingTable       Kind = "missing_table"       // The model's table does not exist
+	MissingColumn      Kind = "missing_column"      // A model field has no matching column
+	ExtraColumn        Kind = "extra_column"        // A column has no matching model field
+	MissingTag         Kind = "missing_db_tag"      // A model field has no db tag
+	TypeMismatch       Kind = "type_mismatch"       // The Go type cannot hold the column type
+	NullableMismatch   Kind = "nullable_mismatch"   // A nullable column maps to a non-nullable field
+	NotNullMismatch    Kind = "not_null_mismatch"   // A NOT NULL column maps to a pointer field
+	UnsupportedGoField Kind = "unsupported_go_type" // The Go type is not known to the checker
+)
+
+// Drift is a single difference between a table and its model.
+type Drift struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Kind   Kind   `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s.%s: %s (%s)", d.Table, d.Column, d.Kind, d.Detail)
+}
+
+// Column describes a database column as reported by information_schema.
+type Column struct {
+	Table    string `db:"table_name"`
+	Name     string `db:"column_name"`
+	DataType string `db:"data_type"`
+	Nullable bool   `db:"nullable"`
+}
+
+// Check loads the columns of every model's table from the current schema and compares them.
+func Check(ctx context.Context, db *sqlx.DB, tables ...models.Tabler) ([]Drift, error) {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.TableName())
+	}
+
+	var columns []Column
+	err := db.SelectContext(ctx, &columns, `
+		SELECT table_name, column_name, data_type, is_nullable = 'YES' AS nullable
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+		ORDER BY table_name, ordinal_position`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(columns, tables...), nil
+}
+
+// Compare reports the drift between the given columns and models.
+func Compare(columns []Column, tables ...models.Tabler) []Drift {
+	byTable := map[string]map[string]Column{}
+	for _, column := range columns {
+		if byTable[column.Table] == nil {
+			byTable[column.Table] = map[string]Column{}
+		}
+		byTable[column.Table][column.Name] = column
+	}
+
+	drifts := []Drift{}
+	for _, table := range tables {
+		name := table.TableName()
+		tableColumns, exists := byTable[name]
+		if !exists {
+			drifts = append(drifts, Drift{Table: name, Kind: MissingTable, Detail: fmt.Sprintf("model %T has no table", table)})
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, field := range modelFields(reflect.TypeOf(table)) {
+			if field.column == "" {
+				drifts = append(drifts, Drift{Table: name, Column: strings.ToLower(field.name), Kind: MissingTag,
+					Detail: fmt.Sprintf("field %s has no db tag", field.name)})
+				continue
+			}
+			seen[field.column] = true
+
+			column, exists := tableColumns[field.column]
+			if !exists {
+				drifts = append(drifts, Drift{Table: name, Column: field.column, Kind: MissingColumn,
+					Detail: fmt.Sprintf("field %s (%s) has no column", field.name, field.typ)})
+				continue
+			}
+			drifts = append(drifts, compareColumn(name, field, column)...)
+		}
+
+		for _, column := range sortedColumns(tableColumns) {
+			if !seen[column.Name] {
+				drifts = append(drifts, Drift{Table: name, Column: column.Name, Kind: ExtraColumn,
+					Detail: fmt.Sprintf("column %s has no model field", column.DataType)})
+			}
+		}
+	}
+
+	return drifts
+}
+
+type field struct {
+	name   string
+	column string
+	typ    reflect.Type
+}
+
+// modelFields flattens embedded structs the same way sqlx does and returns the mapped fields.
+func modelFields(t reflect.Type) []field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := []field{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, modelFields(f.Type)...)
+			continue
+		}
+		fields = append(fields, field{name: f.Name, column: strings.Split(tag, ",")[0], typ: f.Type})
+	}
+	return fields
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// compatibleTypes maps a Go type class to the information_schema data types it can scan.
+var compatibleTypes = map[string][]string{
+	"string": {"character varying", "text", "character", "USER-DEFINED", "point", "jsonb", "json", "uuid"},
+	"int":    {"smallint", "integer", "bigint"},
+	"float":  {"numeric", "real", "double precision"},
+	"bool":   {"boolean"},
+	"time":   {"timestamp with time zone", "timestamp without time zone", "date", "time without time zone", "time with time zone"},
+	"json":   {"jsonb", "json"},
+}
+
+func compareColumn(table string, f field, column Column) []Drift {
+	drifts := []Drift{}
+
+	base, pointer := f.typ, false
+	if base.Kind() == reflect.Pointer {
+		base, pointer = base.Elem(), true
+	}
+
+	class := typeClass(base)
+	switch {
+	case class == "":
+		drifts = append(drifts, Drift{Table: table, Column: column.Name, Kind: UnsupportedGoField,
+			Detail: fmt.Sprintf("field %s has unsupported type %s", f.name, f.typ)})
+	case !contains(compatibleTypes[class], column.DataType):
+		drifts = append(drifts, Drift{Table: table, Column: column.Name, Kind: TypeMismatch,
+			Detail: fmt.Sprintf("field %s is %s but column is %s", f.name, f.typ, column.DataType)})
+	}
+
+	// Maps, slices and Scanner implementations handle NULL themselves
+	nullSafe := pointer || base.Kind() == reflect.Map || base.Kind() == reflect.Slice || reflect.PointerTo(base).Implements(scannerType)
+	if column.Nullable && !nullSafe {
+		drifts = append(drifts, Drift{Table: table, Column: column.Name, Kind: NullableMismatch,
+			Detail: fmt.Sprintf("column is nullable but field %s is %s", f.name, f.typ)})
+	}
+	if !column.Nullable && pointer {
+		drifts = append(drifts, Drift{Table: table, Column: column.Name, Kind: NotNullMismatch,
+			Detail: fmt.Sprintf("column is NOT NULL but field %s is %s", f.name, f.typ)})
+	}
+
+	return drifts
+}
+
+// typeClass groups Go types by the database types they can hold; "" means unknown.
+func typeClass(t reflect.Type) string {
+	if t == timeType {
+		return "time"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Map, reflect.Slice:
+		return "json"
+	}
+	return ""
+}
+
+func sortedColumns(columns map[string]Column) []Column {
+	sorted := make([]Column, 0, len(columns))
+	for _, column := range columns {
+		sorted = append(sorted, column)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/tracing"
+)
+
+type AuditService struct {
+	repo       repositories.AuditLogRepository
+	transactor repositories.Transactor
+}
+
+// NewAuditService initializes a new AuditService.
+func NewAuditService(repo repositories.AuditLogRepository, transactor repositories.Transactor) *AuditService {
+	return &AuditService{repo: repo, transactor: transactor}
+}
+
+// QueryAuditLog searches the audit log and attaches field-level diffs to every entry.
+func (s *AuditService) QueryAuditLog(ctx context.Context, q repositories.AuditLogQuery) (_ []models.AuditEntry, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.QueryAuditLog")
+	defer func() { tracing.End(span, err) }()
+
+	logs, err := s.repo.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return toAuditEntries(logs), nil
+}
+
+// GetFacilityHistory returns the chronological change timeline of a facility and its dependent rows.
+func (s *AuditService) GetFacilityHistory(ctx context.Context, facilityID int64, limit, offset int) (_ []models.AuditEntry, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.GetFacilityHistory")
+	defer func() { tracing.End(span, err) }()
+
+	logs, err := s.repo.FacilityHistory(ctx, facilityID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return toAuditEntries(logs), nil
+}
+
+func toAuditEntries(logs []models.AuditLog) []models.AuditEntry {
+	entries := make([]models.AuditEntry, 0, len(logs))
+	for _, log := range logs {
+		entries = append(entries, models.AuditEntry{
+			AuditLog: log,
+			Changes:  DiffAuditData(log.OldData, log.NewData),
+		})
+	}
+	return entries
+}
+
+// DiffAuditData computes the field-level changes between two row snapshots.
+// Inserts report every new field, deletes every old field, and updates only the fields that differ.
+// Changes are sorted by field name so the output is stable.
+func DiffAuditData(oldData, newData models.JSONMap) []models.FieldChange {
+	fields := make(map[string]struct{}, len(oldData)+len(newData))
+	for field := range oldData {
+		fields[field] = struct{}{}
+	}
+	for field := range newData {
+		fields[field] = struct{}{}
+	}
+
+	changes := []models.FieldChange{}
+	for field := range fields {
+		oldValue, inOld := oldData[field]
+		newValue, inNew := newData[field]
+		if inOld && inNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: field, Old: oldValue, New: newValue})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
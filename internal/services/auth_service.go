@@ -1,10 +1,14 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"server/internal/models"
 	"server/internal/repositories"
 	"server/internal/validators"
+	"server/pkg/apperrors"
+	"server/pkg/tracing"
 	"strconv"
 	"time"
 
@@ -12,16 +16,40 @@ import (
 	"golang.org/x/crypto/bcrypt"  // to hash and compare passwords
 )
 
+var (
+	// ErrUserExists is returned when registering an email or phone number that is already taken.
+	ErrUserExists = apperrors.Conflict("user already exists")
+	// ErrInvalidCredentials is returned for unknown users and wrong passwords alike.
+	ErrInvalidCredentials = apperrors.Unauthorized("invalid credentials")
+	// ErrUserNotFound is returned when a user does not exist.
+	ErrUserNotFound = apperrors.NotFound("user not found")
+	// ErrInvalidToken is returned for unknown, malformed and expired tokens.
+	ErrInvalidToken = apperrors.Unauthorized("invalid or expired token")
+)
+
+// TokenClaims are the verified claims of an access token.
+type TokenClaims struct {
+	UserID string
+	// Role is the user's role when the token was issued; tokens issued before roles existed have none.
+	Role models.UserRole
+}
+
 type AuthService struct {
 	repo repositories.AuthRepository
+	// jwtSecret signs access tokens, which expire after tokenTTL.
+	jwtSecret []byte
+	tokenTTL  time.Duration
 }
 
-func NewAuthService(repo repositories.AuthRepository) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo repositories.AuthRepository, jwtSecret []byte, tokenTTL time.Duration) *AuthService {
+	return &AuthService{repo: repo, jwtSecret: jwtSecret, tokenTTL: tokenTTL}
 }
 
 // User operations
-func (s *AuthService) CreateUser(user *validators.TRegisterRequest) (*models.User, error) {
+func (s *AuthService) CreateUser(ctx context.Context, user *validators.TRegisterRequest) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateUser")
+	defer func() { tracing.End(span, err) }()
+
 	// Hash password before saving
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -33,37 +61,48 @@ func (s *AuthService) CreateUser(user *validators.TRegisterRequest) (*models.Use
 	modelUser.Password = string(hashedPassword)
 
 	// Check if user already exists
-	existingUser, err := s.repo.GetUserByEmailOrPhone(user.Email)
-	if err != nil {
+	_, err = s.repo.GetUserByEmailOrPhone(ctx, user.Email)
+	switch {
+	case err == nil:
+		return nil, ErrUserExists
+	case !errors.Is(err, sql.ErrNoRows):
 		return nil, err
 	}
-	if existingUser != nil {
-		return nil, errors.New("user already exists")
-	}
 
-	createdUser, err := s.repo.CreateUser(modelUser)
+	createdUser, err := s.repo.CreateUser(ctx, modelUser)
 	if err != nil {
 		return nil, err
 	}
 
+	registrationsTotal.Inc()
 	return mapModelToUser(createdUser), nil
 }
 
 // AuthenticateUser validates the user's credentials (login)
-func (s *AuthService) LoginUser(loginRequest *validators.TLoginRequest) (*models.User, error) {
-	// If token is valid, continue to validate credentials
-	user, err := s.repo.GetUserByEmailOrPhone(loginRequest.Email)
+func (s *AuthService) LoginUser(ctx context.Context, loginRequest *validators.TLoginRequest) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.LoginUser")
+	defer func() { tracing.End(span, err) }()
+
+	// Users sign in with either their email or their phone number
+	identifier := loginRequest.Email
+	if identifier == "" {
+		identifier = loginRequest.PhoneNumber
+	}
+	user, err := s.repo.GetUserByEmailOrPhone(ctx, identifier)
+	if errors.Is(err, sql.ErrNoRows) {
+		loginFailuresTotal.WithLabelValues("unknown_user").Inc()
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
+		loginFailuresTotal.WithLabelValues("error").Inc()
 		return nil, err
 	}
-	if user == nil {
-		return nil, errors.New("invalid credentials")
-	}
 
 	// Compare passwords
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Password))
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		loginFailuresTotal.WithLabelValues("wrong_password").Inc()
+		return nil, ErrInvalidCredentials
 	}
 
 	// If everything checks out, return the user details
@@ -71,21 +110,24 @@ func (s *AuthService) LoginUser(loginRequest *validators.TLoginRequest) (*models
 }
 
 // GetUserByID retrieves a user by their ID
-func (s *AuthService) GetUserByID(userID string) (*models.User, error) {
+func (s *AuthService) GetUserByID(ctx context.Context, userID string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetUserByID")
+	defer func() { tracing.End(span, err) }()
+
 	// Convert string userID to integer
 	id, err := strconv.Atoi(userID)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, apperrors.Wrap(err, apperrors.KindValidation, "invalid user ID format")
 	}
 
 	// Fetch the user from the repository using the user ID
-	user, err := s.repo.GetUser(id)
+	user, err := s.repo.GetUser(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, errors.New("user not found")
-	}
 
 	return user, nil
 }
@@ -94,16 +136,16 @@ func (s *AuthService) GetUserByID(userID string) (*models.User, error) {
 func (s *AuthService) GenerateAuthToken(user *models.User) (string, error) {
 	// Define claims
 	claims := jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		"sub":  user.ID,
+		"role": user.Role,
+		"exp":  time.Now().Add(s.tokenTTL).Unix(),
 	}
 
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secret := []byte("your_secret_key") // Replace with a secret key from config
 
 	// Generate signed token string
-	tokenString, err := token.SignedString(secret)
+	tokenString, err := token.SignedString(s.jwtSecret)
 	if err != nil {
 		return "", err
 	}
@@ -112,13 +154,16 @@ func (s *AuthService) GenerateAuthToken(user *models.User) (string, error) {
 }
 
 // Verification token operations (unchanged)
-func (s *AuthService) CreateVerificationToken(token *validators.TVerificationToken) (*validators.TVerificationToken, error) {
+func (s *AuthService) CreateVerificationToken(ctx context.Context, token *validators.TVerificationToken) (_ *validators.TVerificationToken, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateVerificationToken")
+	defer func() { tracing.End(span, err) }()
+
 	modelToken, err := mapVerificationTokenToModel(token)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.Wrap(err, apperrors.KindValidation, "expires must be an RFC3339 timestamp")
 	}
 
-	created, err := s.repo.CreateVerificationToken(modelToken)
+	created, err := s.repo.CreateVerificationToken(ctx, modelToken)
 	if err != nil {
 		return nil, err
 	}
@@ -127,19 +172,22 @@ func (s *AuthService) CreateVerificationToken(token *validators.TVerificationTok
 }
 
 // VerifyToken verifies a token (e.g., email/phone verification)
-func (s *AuthService) VerifyToken(identifier, token string) (bool, error) {
+func (s *AuthService) VerifyToken(ctx context.Context, identifier, token string) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyToken")
+	defer func() { tracing.End(span, err) }()
+
 	// Fetch the verification token from the repository using the identifier and token
-	verificationToken, err := s.repo.UseVerificationToken(identifier, token)
+	verificationToken, err := s.repo.UseVerificationToken(ctx, identifier, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrInvalidToken
+	}
 	if err != nil {
 		return false, err
 	}
-	if verificationToken == nil {
-		return false, errors.New("invalid token")
-	}
 
 	// Check if the token has expired
 	if verificationToken.Expires.Before(time.Now()) {
-		return false, errors.New("token has expired")
+		return false, ErrInvalidToken
 	}
 
 	return true, nil
@@ -166,6 +214,7 @@ func mapModelToUser(user *models.User) *models.User {
 		PhoneNumber:   user.PhoneNumber,
 		EmailVerified: user.EmailVerified,
 		Image:         user.Image,
+		Role:          user.Role,
 	}
 }
 
@@ -191,38 +240,43 @@ func mapModelToVerificationToken(token *models.VerificationToken) *validators.TV
 	}
 }
 
-// ValidateAuthToken validates a JWT token and returns the user ID if the token is valid.
-func (s *AuthService) ValidateAuthToken(tokenString string) (string, error) {
+// ValidateAuthToken validates a JWT token and returns its claims if the token is valid.
+func (s *AuthService) ValidateAuthToken(tokenString string) (TokenClaims, error) {
 	// Parse the token
-	secret := []byte("your_secret_key") // Replace with a secret key from config
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Ensure the token's signing method is valid
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return secret, nil
+		return s.jwtSecret, nil
 	})
 	if err != nil {
-		return "", err
+		return TokenClaims{}, apperrors.Wrap(err, apperrors.KindUnauthorized, ErrInvalidToken.Message)
 	}
 
 	// Validate the token
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		// Extract user ID (subject)
-		userID, ok := claims["sub"].(string)
-		if !ok {
-			return "", errors.New("invalid token claims")
+		// Extract user ID (subject); GenerateAuthToken encodes it as a JSON number
+		var userID string
+		switch sub := claims["sub"].(type) {
+		case string:
+			userID = sub
+		case float64:
+			userID = strconv.FormatInt(int64(sub), 10)
+		default:
+			return TokenClaims{}, ErrInvalidToken
 		}
 
 		// Check if the token is expired
 		if exp, ok := claims["exp"].(float64); ok {
 			if time.Unix(int64(exp), 0).Before(time.Now()) {
-				return "", errors.New("token has expired")
+				return TokenClaims{}, ErrInvalidToken
 			}
 		}
 
-		return userID, nil
+		role, _ := claims["role"].(string)
+		return TokenClaims{UserID: userID, Role: models.UserRole(role)}, nil
 	}
 
-	return "", errors.New("invalid token")
+	return TokenClaims{}, ErrInvalidToken
 }
@@ -1,11 +1,18 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"server/internal/models"
 	"server/internal/repositories"
+	"server/pkg/apperrors"
+	"server/pkg/tracing"
 )
 
+// ErrCityNotFound is returned when a city does not exist.
+var ErrCityNotFound = apperrors.NotFound("city not found")
+
 type CityService struct {
 	repo repositories.CitiesRepository
 }
@@ -14,10 +21,16 @@ func NewCityService(repo repositories.CitiesRepository) *CityService {
 	return &CityService{repo: repo}
 }
 
-func (s *CityService) GetCityByID(id int64) (*models.City, error) {
-	city, err := s.repo.Find(id)
+func (s *CityService) GetCityByID(ctx context.Context, id int64) (_ *models.City, err error) {
+	ctx, span := tracing.Start(ctx, "CityService.GetCityByID")
+	defer func() { tracing.End(span, err) }()
+
+	city, err := s.repo.Find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCityNotFound
+	}
 	if err != nil {
-		return nil, errors.New("city not found")
+		return nil, err
 	}
 	return city, nil
 }
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/apperrors"
+	"server/pkg/tracing"
+)
+
+var (
+	// ErrAppointmentNotFound is returned when an appointment does not exist at the facility.
+	ErrAppointmentNotFound = apperrors.NotFound("appointment not found")
+	// ErrAppointmentNotOpen is returned when changing an appointment that is no longer scheduled.
+	ErrAppointmentNotOpen = apperrors.Conflict("appointment is no longer scheduled")
+	// ErrAppointmentNotDue is returned when marking an appointment that has not started as a no-show.
+	ErrAppointmentNotDue = apperrors.Conflict("appointment has not started yet")
+	// ErrAppointmentNotOwned is returned when changing an appointment someone else booked.
+	ErrAppointmentNotOwned = apperrors.Forbidden("only the user who booked the appointment or staff may change it")
+)
+
+// unknownCity labels the metrics of facilities without a city.
+const unknownCity = "unknown"
+
+// BookAppointment schedules appointment at the facility for the actor's user, who then owns it.
+// Every attempt is counted, and failures are counted by the kind of their error.
+func (s *FacilityService) BookAppointment(ctx context.Context, actor repositories.Actor, facilityID int64, appointment models.FacilityAppointment) (_ *models.FacilityAppointment, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.BookAppointment")
+	defer func() { tracing.End(span, err) }()
+
+	bookingAttemptsTotal.Inc()
+	defer func() {
+		if err != nil {
+			bookingFailuresTotal.WithLabelValues(string(apperrors.KindOf(err))).Inc()
+		}
+	}()
+
+	var facility *models.Facility
+	var city string
+
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		var err error
+		if facility, city, err = findFacilityAndCity(ctx, uow, facilityID); err != nil {
+			return err
+		}
+		if appointment.DoctorID != nil {
+			_, err := uow.Doctors().Find(ctx, *appointment.DoctorID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrDoctorNotFound
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		appointment.FacilityID = facilityID
+		appointment.Status = models.Scheduled
+		appointment.BookedBy = actor.UserID
+		_, err = uow.FacilityAppointments().Create(ctx, &appointment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordBooking("created", facility, city)
+	return &appointment, nil
+}
+
+// CancelAppointment cancels a scheduled appointment at the facility. Only the user who booked it,
+// staff and admins may cancel it.
+func (s *FacilityService) CancelAppointment(ctx context.Context, actor repositories.Actor, facilityID, appointmentID int64) (_ *models.FacilityAppointment, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.CancelAppointment")
+	defer func() { tracing.End(span, err) }()
+
+	return s.closeAppointment(ctx, actor, facilityID, appointmentID, models.Cancelled, "cancelled")
+}
+
+// MarkAppointmentNoShow records that the patient of a scheduled appointment at the facility did
+// not come. The appointment must have started.
+func (s *FacilityService) MarkAppointmentNoShow(ctx context.Context, actor repositories.Actor, facilityID, appointmentID int64) (_ *models.FacilityAppointment, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.MarkAppointmentNoShow")
+	defer func() { tracing.End(span, err) }()
+
+	return s.closeAppointment(ctx, actor, facilityID, appointmentID, models.NoShow, "no_show")
+}
+
+// closeAppointment moves a scheduled appointment to status and counts it as event. The actor must
+// own the appointment or be staff.
+func (s *FacilityService) closeAppointment(ctx context.Context, actor repositories.Actor, facilityID, appointmentID int64, status models.AppointmentStatus, event string) (*models.FacilityAppointment, error) {
+	var appointment *models.FacilityAppointment
+	var facility *models.Facility
+	var city string
+
+	err := s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		existing, err := uow.FacilityAppointments().Find(ctx, appointmentID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && existing.FacilityID != facilityID) {
+			return ErrAppointmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !mayChangeAppointment(actor, existing) {
+			return ErrAppointmentNotOwned
+		}
+		if existing.Status != models.Scheduled && existing.Status != models.Rescheduled {
+			return ErrAppointmentNotOpen
+		}
+		if status == models.NoShow && existing.AppointmentTime.After(time.Now()) {
+			return ErrAppointmentNotDue
+		}
+
+		if facility, city, err = findFacilityAndCity(ctx, uow, facilityID); err != nil {
+			return err
+		}
+		appointment, err = uow.FacilityAppointments().Update(ctx, appointmentID, map[string]interface{}{"status": status})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordBooking(event, facility, city)
+	return appointment, nil
+}
+
+// mayChangeAppointment reports whether the actor booked the appointment or is staff.
+func mayChangeAppointment(actor repositories.Actor, appointment *models.FacilityAppointment) bool {
+	if actor.Role == models.RoleStaff || actor.Role == models.RoleAdmin {
+		return true
+	}
+	return actor.UserID != nil && appointment.BookedBy != nil && *actor.UserID == *appointment.BookedBy
+}
+
+// AddFacilityReview stores a review of the facility written by the actor's user.
+func (s *FacilityService) AddFacilityReview(ctx context.Context, actor repositories.Actor, facilityID int64, rating float64, comment *string) (_ *models.Review, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.AddFacilityReview")
+	defer func() { tracing.End(span, err) }()
+
+	review := models.Review{EntityType: "facility", EntityID: facilityID, UserID: actor.UserID, Rating: &rating, Comment: comment}
+	var facility *models.Facility
+
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		var err error
+		facility, err = uow.Facilities().Find(ctx, facilityID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFacilityNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = uow.Reviews().Create(ctx, &review)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reviewsSubmittedTotal.WithLabelValues(string(facility.Type)).Inc()
+	return &review, nil
+}
+
+// findFacilityAndCity fetches a facility and the name of its city.
+func findFacilityAndCity(ctx context.Context, uow *repositories.UnitOfWork, facilityID int64) (*models.Facility, string, error) {
+	facility, err := uow.Facilities().Find(ctx, facilityID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrFacilityNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if facility.CityID == nil {
+		return facility, unknownCity, nil
+	}
+
+	city, err := uow.Cities().Find(ctx, *facility.CityID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return facility, unknownCity, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return facility, city.Name, nil
+}
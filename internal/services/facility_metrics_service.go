@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/apperrors"
+	"server/pkg/logger"
+	"server/pkg/tracing"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxMetricsClockSkew is how far in the future a sample may be measured, to allow for the
+	// clocks of reporting systems running ahead.
+	maxMetricsClockSkew = 5 * time.Minute
+	// defaultMetricsRange and defaultMetricsBucket apply to queries that do not set them.
+	defaultMetricsRange  = 24 * time.Hour
+	defaultMetricsBucket = time.Hour
+	// minMetricsBucket and maxMetricsBuckets bound the resolution of a query.
+	minMetricsBucket  = time.Minute
+	maxMetricsBuckets = 1000
+	// latestMetricsWindow is how old the latest sample of a metric may be to still be shown.
+	latestMetricsWindow = 24 * time.Hour
+	// metricsPartitionsAhead is how many days ahead the maintenance job creates partitions for.
+	metricsPartitionsAhead = 7
+)
+
+type FacilityMetricsService struct {
+	repo       repositories.FacilityMetricsRepository
+	facilities repositories.FacilityRepository
+	retention  time.Duration
+}
+
+// NewFacilityMetricsService initializes a new FacilityMetricsService. Samples are kept for retention;
+// older ones are rejected on ingestion and dropped by the maintenance job.
+func NewFacilityMetricsService(repo repositories.FacilityMetricsRepository, facilities repositories.FacilityRepository, retention time.Duration) *FacilityMetricsService {
+	return &FacilityMetricsService{repo: repo, facilities: facilities, retention: retention}
+}
+
+// IngestMetrics stores a batch of samples reported by the facility and returns how many were
+// stored. Samples must have been measured within the retention period and not in the future.
+// A sample sent again for the same metric and time replaces the earlier one, also within the batch.
+func (s *FacilityMetricsService) IngestMetrics(ctx context.Context, facilityID int64, samples []models.FacilityMetrics) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityMetricsService.IngestMetrics")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.findFacility(ctx, facilityID); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	oldest, newest := now.Add(-s.retention), now.Add(maxMetricsClockSkew)
+	type sampleKey struct {
+		metricType models.FacilityMetricType
+		measuredAt time.Time
+	}
+	positions := map[sampleKey]int{}
+	batch := make([]models.FacilityMetrics, 0, len(samples))
+	var fieldErrors []apperrors.FieldError
+
+	for i, sample := range samples {
+		switch {
+		case sample.MeasuredAt.Before(oldest) || sample.MeasuredAt.After(newest):
+			fieldErrors = append(fieldErrors, apperrors.FieldError{Field: fmt.Sprintf("metrics[%d].measured_at", i),
+				Message: "measured_at must be within the retention period and not in the future"})
+		case sample.MetricType == models.BedOccupancy && sample.MetricValue > 100:
+			fieldErrors = append(fieldErrors, apperrors.FieldError{Field: fmt.Sprintf("metrics[%d].metric_value", i),
+				Message: "bed_occupancy is a percentage and must not exceed 100"})
+		}
+
+		sample.FacilityID = facilityID
+		sample.MeasuredAt = sample.MeasuredAt.UTC()
+		key := sampleKey{sample.MetricType, sample.MeasuredAt}
+		if position, seen := positions[key]; seen {
+			batch[position] = sample
+			continue
+		}
+		positions[key] = len(batch)
+		batch = append(batch, sample)
+	}
+	if len(fieldErrors) > 0 {
+		return 0, apperrors.Validation("invalid metrics", fieldErrors...)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	// Partitions for the days ahead are created by the maintenance job; back-filled days may be missing
+	from, to := batch[0].MeasuredAt, batch[0].MeasuredAt
+	for _, sample := range batch[1:] {
+		if sample.MeasuredAt.Before(from) {
+			from = sample.MeasuredAt
+		}
+		if sample.MeasuredAt.After(to) {
+			to = sample.MeasuredAt
+		}
+	}
+	if err := s.repo.EnsurePartitions(ctx, from, to); err != nil {
+		return 0, err
+	}
+	return s.repo.Insert(ctx, batch)
+}
+
+// QueryMetrics summarizes the samples of the facility in q's range in buckets of q's width. The
+// range defaults to the last day and the bucket width to an hour.
+func (s *FacilityMetricsService) QueryMetrics(ctx context.Context, q repositories.FacilityMetricsQuery) (_ []models.FacilityMetricBucket, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityMetricsService.QueryMetrics")
+	defer func() { tracing.End(span, err) }()
+
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultMetricsRange)
+	}
+	if q.Bucket == 0 {
+		q.Bucket = defaultMetricsBucket
+	}
+
+	switch {
+	case !q.From.Before(q.To):
+		return nil, apperrors.Validation("from must be before to")
+	case q.Bucket < minMetricsBucket:
+		return nil, apperrors.Validation(fmt.Sprintf("bucket must be at least %s", minMetricsBucket))
+	case (q.To.Sub(q.From)-1)/q.Bucket >= maxMetricsBuckets:
+		return nil, apperrors.Validation(fmt.Sprintf("the range spans more than %d buckets; use wider buckets", maxMetricsBuckets))
+	}
+
+	if err := s.findFacility(ctx, q.FacilityID); err != nil {
+		return nil, err
+	}
+	return s.repo.Buckets(ctx, q)
+}
+
+// LatestMetrics returns the most recent sample of every metric the facility reported in the last
+// day, such as its current emergency room wait.
+func (s *FacilityMetricsService) LatestMetrics(ctx context.Context, facilityID int64) (_ []models.FacilityMetrics, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityMetricsService.LatestMetrics")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.findFacility(ctx, facilityID); err != nil {
+		return nil, err
+	}
+	return s.repo.Latest(ctx, facilityID, time.Now().Add(-latestMetricsWindow))
+}
+
+// MaintainPartitions creates the partitions of the coming days and drops those whose samples are
+// all older than the retention period. It returns the names of the dropped partitions.
+func (s *FacilityMetricsService) MaintainPartitions(ctx context.Context) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityMetricsService.MaintainPartitions")
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now()
+	if err := s.repo.EnsurePartitions(ctx, now, now.AddDate(0, 0, metricsPartitionsAhead)); err != nil {
+		return nil, fmt.Errorf("create partitions: %w", err)
+	}
+	return s.repo.DropPartitionsBefore(ctx, now.Add(-s.retention))
+}
+
+// RunMaintenanceJob maintains the partitions of the facility metrics every interval until ctx is done.
+func (s *FacilityMetricsService) RunMaintenanceJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dropped, err := s.MaintainPartitions(ctx)
+			if err != nil {
+				logger.Error("Facility metrics maintenance failed", zap.Error(err))
+				continue
+			}
+			logger.Info("Facility metrics maintained", zap.Strings("dropped_partitions", dropped))
+		}
+	}
+}
+
+// findFacility checks that the facility exists.
+func (s *FacilityMetricsService) findFacility(ctx context.Context, facilityID int64) error {
+	_, err := s.facilities.Find(ctx, facilityID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrFacilityNotFound
+	}
+	return err
+}
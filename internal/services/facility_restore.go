@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/apperrors"
+	"server/pkg/tracing"
+)
+
+// ErrFacilitySnapshotNotFound is returned when a facility did not exist at the requested time.
+var ErrFacilitySnapshotNotFound = apperrors.NotFound("facility did not exist at the requested time")
+
+// GetFacilityAsOf reconstructs a facility and its departments, operating hours and equipment
+// from the audit log as they were at asOf.
+func (s *AuditService) GetFacilityAsOf(ctx context.Context, facilityID int64, asOf time.Time) (_ *models.FacilitySnapshot, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.GetFacilityAsOf")
+	defer func() { tracing.End(span, err) }()
+
+	return facilityAsOf(ctx, s.repo, facilityID, asOf)
+}
+
+// RestoreFacility replays the facility's state as of asOf in a single audited transaction.
+// Rows that existed at asOf are upserted and dependent rows created afterwards are removed.
+// Every resulting row change is audited with the actor and a restore reason. The facility row is
+// locked and the snapshot read inside the transaction, so concurrent writes cannot slip in between.
+func (s *AuditService) RestoreFacility(ctx context.Context, actor repositories.Actor, facilityID int64, asOf time.Time) (_ *models.FacilitySnapshot, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.RestoreFacility")
+	defer func() { tracing.End(span, err) }()
+
+	actor.Reason = fmt.Sprintf("restore facility %d as of %s", facilityID, asOf.UTC().Format(time.RFC3339))
+
+	var snapshot *models.FacilitySnapshot
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		snapshots := uow.Snapshots()
+
+		// Writes to the facility wait until the restore commits; the snapshot is read only after
+		// the lock is held, so every change committed before it is replayed over, not lost
+		if err := snapshots.Lock(ctx, "facilities", facilityID); err != nil {
+			return err
+		}
+		var err error
+		if snapshot, err = facilityAsOf(ctx, uow.AuditLog(), facilityID, asOf); err != nil {
+			return err
+		}
+
+		// Snapshots taken before soft delete existed have no deleted_at; the facility was not trashed then
+		if _, ok := snapshot.Facility["deleted_at"]; !ok {
+			snapshot.Facility["deleted_at"] = nil
+		}
+		if err := snapshots.Upsert(ctx, "facilities", snapshot.Facility); err != nil {
+			return err
+		}
+
+		// Remove children that did not exist at asOf before re-creating the ones that did,
+		// so unique constraints such as (facility_id, department_id, day_of_week) cannot collide.
+		if _, err := snapshots.DeleteExcept(ctx, "facility_operating_hours", "facility_id", facilityID, rowIDs(snapshot.OperatingHours)); err != nil {
+			return err
+		}
+		if _, err := snapshots.DeleteExcept(ctx, "facility_equipment", "facility_id", facilityID, rowIDs(snapshot.Equipment)); err != nil {
+			return err
+		}
+
+		// Departments go first because hours and equipment reference them
+		children := []struct {
+			table string
+			rows  []models.JSONMap
+		}{
+			{"facility_departments", snapshot.Departments},
+			{"facility_operating_hours", snapshot.OperatingHours},
+			{"facility_equipment", snapshot.Equipment},
+		}
+		for _, child := range children {
+			for _, row := range child.rows {
+				if err := snapshots.Upsert(ctx, child.table, row); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = snapshots.DeleteExcept(ctx, "facility_departments", "facility_id", facilityID, rowIDs(snapshot.Departments))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// facilityAsOf reads the facility snapshot at asOf through repo.
+func facilityAsOf(ctx context.Context, repo repositories.AuditLogRepository, facilityID int64, asOf time.Time) (*models.FacilitySnapshot, error) {
+	facilities, err := latestRowsAsOf(ctx, repo, "facilities", "id", facilityID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(facilities) == 0 {
+		return nil, ErrFacilitySnapshotNotFound
+	}
+
+	snapshot := &models.FacilitySnapshot{AsOf: asOf, Facility: facilities[0]}
+	if snapshot.Departments, err = latestRowsAsOf(ctx, repo, "facility_departments", "facility_id", facilityID, asOf); err != nil {
+		return nil, err
+	}
+	if snapshot.OperatingHours, err = latestRowsAsOf(ctx, repo, "facility_operating_hours", "facility_id", facilityID, asOf); err != nil {
+		return nil, err
+	}
+	if snapshot.Equipment, err = latestRowsAsOf(ctx, repo, "facility_equipment", "facility_id", facilityID, asOf); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// latestRowsAsOf returns the row snapshots of table that existed at asOf, skipping deleted rows.
+func latestRowsAsOf(ctx context.Context, repo repositories.AuditLogRepository, table, key string, value int64, asOf time.Time) ([]models.JSONMap, error) {
+	logs, err := repo.LatestAsOf(ctx, table, key, value, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []models.JSONMap{}
+	for _, log := range logs {
+		if log.Operation == "DELETE" || log.NewData == nil {
+			continue
+		}
+		rows = append(rows, log.NewData)
+	}
+	return rows, nil
+}
+
+// rowIDs collects the IDs of the given row snapshots.
+func rowIDs(rows []models.JSONMap) []int64 {
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		if id, ok := row.Int64("id"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -1,22 +1,40 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"server/internal/models"
 	"server/internal/repositories"
+	"server/pkg/apperrors"
+	"server/pkg/tracing"
+	"time"
+)
+
+var (
+	// ErrFacilityNotFound is returned when a facility does not exist.
+	ErrFacilityNotFound = apperrors.NotFound("facility not found")
+	// ErrDepartmentNotFound is returned when a department does not exist.
+	ErrDepartmentNotFound = apperrors.NotFound("department not found")
+	// ErrDoctorNotFound is returned when a doctor does not exist.
+	ErrDoctorNotFound = apperrors.NotFound("doctor not found")
 )
 
 type FacilityService struct {
-	repo repositories.FacilityRepository
+	repo       repositories.FacilityRepository
+	transactor repositories.Transactor
 }
 
 // NewFacilityService initializes a new FacilityService.
-func NewFacilityService(repo repositories.FacilityRepository) *FacilityService {
-	return &FacilityService{repo: repo}
+func NewFacilityService(repo repositories.FacilityRepository, transactor repositories.Transactor) *FacilityService {
+	return &FacilityService{repo: repo, transactor: transactor}
 }
 
-func (s *FacilityService) GetAllFacilities() (*[]models.Facility, error) {
-	facilites, err := s.repo.FindMany((map[string]interface{}{}))
+func (s *FacilityService) GetAllFacilities(ctx context.Context) (_ *[]models.Facility, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.GetAllFacilities")
+	defer func() { tracing.End(span, err) }()
+
+	facilites, err := s.repo.FindMany(ctx, map[string]interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -25,10 +43,163 @@ func (s *FacilityService) GetAllFacilities() (*[]models.Facility, error) {
 }
 
 // GetFacilityByID fetches a facility by its ID.
-func (s *FacilityService) GetFacilityByID(id int64) (*models.Facility, error) {
-	facility, err := s.repo.Find(id)
+func (s *FacilityService) GetFacilityByID(ctx context.Context, id int64) (_ *models.Facility, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.GetFacilityByID")
+	defer func() { tracing.End(span, err) }()
+
+	facility, err := s.repo.Find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFacilityNotFound
+	}
 	if err != nil {
-		return nil, errors.New("facility not found")
+		return nil, err
 	}
 	return facility, nil
 }
+
+// SearchFacilities fetches the facilities matching q. Searches are counted by their first page, and
+// those that find nothing are counted apart, so the share of fruitless searches can be watched.
+func (s *FacilityService) SearchFacilities(ctx context.Context, q repositories.FacilitySearch) (_ []models.Facility, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.SearchFacilities")
+	defer func() { tracing.End(span, err) }()
+
+	facilities, err := s.repo.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Offset == 0 {
+		facilitySearchesTotal.Inc()
+		if len(facilities) == 0 {
+			facilitySearchZeroResultsTotal.Inc()
+		}
+	}
+	return facilities, nil
+}
+
+// UpdateFacility applies updates to a facility. When version is set, the update only succeeds if
+// the facility's updated_at still equals it, and fails with repositories.ErrVersionConflict otherwise.
+func (s *FacilityService) UpdateFacility(ctx context.Context, actor repositories.Actor, id int64, version *time.Time, updates map[string]interface{}) (_ *models.Facility, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.UpdateFacility")
+	defer func() { tracing.End(span, err) }()
+
+	var facility *models.Facility
+
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		var err error
+		if version != nil {
+			facility, err = uow.Facilities().CompareAndUpdate(ctx, id, *version, updates)
+		} else {
+			facility, err = uow.Facilities().Update(ctx, id, updates)
+		}
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrFacilityNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return apperrors.Wrap(err, apperrors.KindPreconditionFailed, "Facility was modified by another request; fetch it again and retry")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return facility, nil
+}
+
+// DeleteFacility moves a facility to the trash, from where an admin can restore it until it is purged.
+func (s *FacilityService) DeleteFacility(ctx context.Context, actor repositories.Actor, id int64) (err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.DeleteFacility")
+	defer func() { tracing.End(span, err) }()
+
+	return s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		_, err := uow.Facilities().Delete(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFacilityNotFound
+		}
+		return err
+	})
+}
+
+// CreateFacilityWithDetails creates a facility with its departments, operating hours and insurance
+// providers in one transaction, so a failure leaves no partially created facility behind.
+func (s *FacilityService) CreateFacilityWithDetails(ctx context.Context, actor repositories.Actor, details models.FacilityDetails) (_ *models.FacilityDetails, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.CreateFacilityWithDetails")
+	defer func() { tracing.End(span, err) }()
+
+	var created models.FacilityDetails
+
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		facility := details.Facility
+		if _, err := uow.Facilities().Create(ctx, &facility); err != nil {
+			return err
+		}
+
+		departments := make([]models.FacilityDepartment, len(details.Departments))
+		for i, department := range details.Departments {
+			department.FacilityID = facility.ID
+			departments[i] = department
+		}
+		hours := make([]models.FacilityOperatingHours, len(details.OperatingHours))
+		for i, hour := range details.OperatingHours {
+			hour.FacilityID = facility.ID
+			hours[i] = hour
+		}
+		providers := make([]models.FacilityInsuranceProvider, len(details.InsuranceProviders))
+		for i, provider := range details.InsuranceProviders {
+			provider.FacilityID = facility.ID
+			providers[i] = provider
+		}
+
+		created = models.FacilityDetails{Facility: facility}
+		var err error
+		if created.Departments, err = uow.FacilityDepartments().CreateMany(ctx, departments); err != nil {
+			return err
+		}
+		if created.OperatingHours, err = uow.FacilityOperatingHours().CreateMany(ctx, hours); err != nil {
+			return err
+		}
+		created.InsuranceProviders, err = uow.FacilityInsuranceProviders().CreateMany(ctx, providers)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// AssignDepartmentHead makes the doctor the head of the department and assigns the doctor to the
+// department's facility in one transaction.
+func (s *FacilityService) AssignDepartmentHead(ctx context.Context, actor repositories.Actor, departmentID, doctorID int64) (_ *models.FacilityDepartment, err error) {
+	ctx, span := tracing.Start(ctx, "FacilityService.AssignDepartmentHead")
+	defer func() { tracing.End(span, err) }()
+
+	var department *models.FacilityDepartment
+
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		existing, err := uow.FacilityDepartments().Find(ctx, departmentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDepartmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = uow.Doctors().Update(ctx, doctorID, map[string]interface{}{"primary_facility_id": existing.FacilityID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDoctorNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		department, err = uow.FacilityDepartments().Update(ctx, departmentID, map[string]interface{}{"head_doctor_id": doctorID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return department, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"server/internal/models"
+	"server/pkg/apperrors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Business metrics are recorded once the operation they count has committed, so retried
+// transactions are counted once.
+var (
+	// bookingsTotal counts appointments by event: created, cancelled or no_show.
+	bookingsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bookings_total",
+			Help: "Total number of appointment bookings, cancellations and no-shows by facility type and city",
+		},
+		[]string{"event", "facility_type", "city"},
+	)
+
+	bookingAttemptsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "booking_attempts_total",
+			Help: "Total number of attempts to book an appointment",
+		},
+	)
+
+	// bookingFailuresTotal counts failed bookings by the kind of their error, such as not_found.
+	bookingFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "booking_failures_total",
+			Help: "Total number of appointment bookings that failed, by reason",
+		},
+		[]string{"reason"},
+	)
+
+	facilitySearchesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "facility_searches_total",
+			Help: "Total number of facility searches",
+		},
+	)
+
+	facilitySearchZeroResultsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "facility_search_zero_results_total",
+			Help: "Total number of facility searches that found nothing",
+		},
+	)
+
+	registrationsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "user_registrations_total",
+			Help: "Total number of users registered",
+		},
+	)
+
+	// loginFailuresTotal counts failed logins by reason: unknown_user, wrong_password or error.
+	loginFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_failures_total",
+			Help: "Total number of failed logins by reason",
+		},
+		[]string{"reason"},
+	)
+
+	reviewsSubmittedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reviews_submitted_total",
+			Help: "Total number of facility reviews submitted by facility type",
+		},
+		[]string{"facility_type"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(bookingsTotal, bookingAttemptsTotal, bookingFailuresTotal, facilitySearchesTotal,
+		facilitySearchZeroResultsTotal, registrationsTotal, loginFailuresTotal, reviewsSubmittedTotal)
+
+	// Export the series alerts divide by before the first failure happens
+	for _, reason := range []string{"unknown_user", "wrong_password", "error"} {
+		loginFailuresTotal.WithLabelValues(reason)
+	}
+	for _, kind := range []apperrors.Kind{apperrors.KindNotFound, apperrors.KindForbidden, apperrors.KindConflict, apperrors.KindValidation, apperrors.KindInternal} {
+		bookingFailuresTotal.WithLabelValues(string(kind))
+	}
+}
+
+// recordBooking counts an appointment event at facility, located in city.
+func recordBooking(event string, facility *models.Facility, city string) {
+	bookingsTotal.WithLabelValues(event, string(facility.Type), city).Inc()
+}
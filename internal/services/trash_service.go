@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/apperrors"
+	"server/pkg/logger"
+	"server/pkg/tracing"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnknownTrashEntity is returned for entities that are not soft-deleted.
+	ErrUnknownTrashEntity = apperrors.NotFound("entity has no trash")
+	// ErrNotInTrash is returned when restoring a record that is not trashed.
+	ErrNotInTrash = apperrors.NotFound("record is not in the trash")
+)
+
+// trashBin gives uniform access to the trash of one soft-deleted entity.
+type trashBin interface {
+	list(ctx context.Context, repos *repositories.UnitOfWork) (interface{}, error)
+	restore(ctx context.Context, repos *repositories.UnitOfWork, id int64) (interface{}, error)
+	purge(ctx context.Context, repos *repositories.UnitOfWork, before time.Time) (int64, error)
+}
+
+// softDeleteBin is the trashBin of the entity whose repository it returns.
+type softDeleteBin[T any] func(repos *repositories.UnitOfWork) repositories.SoftDeleteRepository[T]
+
+func (b softDeleteBin[T]) list(ctx context.Context, repos *repositories.UnitOfWork) (interface{}, error) {
+	return b(repos).FindDeleted(ctx, map[string]interface{}{})
+}
+
+func (b softDeleteBin[T]) restore(ctx context.Context, repos *repositories.UnitOfWork, id int64) (interface{}, error) {
+	return b(repos).Restore(ctx, id)
+}
+
+func (b softDeleteBin[T]) purge(ctx context.Context, repos *repositories.UnitOfWork, before time.Time) (int64, error) {
+	return b(repos).Purge(ctx, before)
+}
+
+// trashBins maps the entity names used in admin routes to their trash.
+var trashBins = map[string]trashBin{
+	"facilities": softDeleteBin[models.Facility](func(u *repositories.UnitOfWork) repositories.SoftDeleteRepository[models.Facility] {
+		return u.Facilities()
+	}),
+	"doctors": softDeleteBin[models.Doctor](func(u *repositories.UnitOfWork) repositories.SoftDeleteRepository[models.Doctor] {
+		return u.Doctors()
+	}),
+	"reviews": softDeleteBin[models.Review](func(u *repositories.UnitOfWork) repositories.SoftDeleteRepository[models.Review] {
+		return u.Reviews()
+	}),
+}
+
+type TrashService struct {
+	repos      *repositories.UnitOfWork
+	transactor repositories.Transactor
+	retention  time.Duration
+}
+
+// NewTrashService initializes a new TrashService. Trashed records older than retention are purged.
+func NewTrashService(db repositories.DBTX, transactor repositories.Transactor, retention time.Duration) *TrashService {
+	return &TrashService{repos: repositories.NewUnitOfWork(db), transactor: transactor, retention: retention}
+}
+
+// ListTrash returns the trashed records of entity, most recently deleted first.
+func (s *TrashService) ListTrash(ctx context.Context, entity string) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "TrashService.ListTrash")
+	defer func() { tracing.End(span, err) }()
+
+	bin, ok := trashBins[entity]
+	if !ok {
+		return nil, ErrUnknownTrashEntity
+	}
+	return bin.list(ctx, s.repos)
+}
+
+// RestoreFromTrash takes a record of entity out of the trash, attributing the change to actor.
+func (s *TrashService) RestoreFromTrash(ctx context.Context, actor repositories.Actor, entity string, id int64) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreFromTrash")
+	defer func() { tracing.End(span, err) }()
+
+	bin, ok := trashBins[entity]
+	if !ok {
+		return nil, ErrUnknownTrashEntity
+	}
+
+	var restored interface{}
+	err = s.transactor.WithinTransaction(ctx, repositories.TxOptions{Actor: &actor}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		var err error
+		restored, err = bin.restore(ctx, uow, id)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotInTrash
+	}
+	return restored, err
+}
+
+// PurgeTrash hard-deletes every record trashed longer than the retention period and returns
+// the number of records removed per entity.
+func (s *TrashService) PurgeTrash(ctx context.Context) (_ map[string]int64, err error) {
+	ctx, span := tracing.Start(ctx, "TrashService.PurgeTrash")
+	defer func() { tracing.End(span, err) }()
+
+	before := time.Now().Add(-s.retention)
+	purged := map[string]int64{}
+
+	// Doctors before facilities: a facility cannot be removed while a doctor still references it
+	for _, entity := range []string{"reviews", "doctors", "facilities"} {
+		n, err := trashBins[entity].purge(ctx, s.repos, before)
+		if err != nil {
+			return purged, fmt.Errorf("purge %s: %w", entity, err)
+		}
+		purged[entity] = n
+	}
+	return purged, nil
+}
+
+// RunPurgeJob purges the trash every interval until ctx is done.
+func (s *TrashService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeTrash(ctx)
+			if err != nil {
+				logger.Error("Trash purge failed", zap.Error(err))
+				continue
+			}
+			logger.Info("Trash purged",
+				zap.Int64("facilities", purged["facilities"]),
+				zap.Int64("doctors", purged["doctors"]),
+				zap.Int64("reviews", purged["reviews"]),
+			)
+		}
+	}
+}
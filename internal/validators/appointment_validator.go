@@ -0,0 +1,27 @@
+package validators
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+// AppointmentRequest is the body of an appointment booking. The appointment must be in the future.
+type AppointmentRequest struct {
+	PatientName          string    `json:"patient_name" validate:"required,max=200"`
+	PatientContact       *string   `json:"patient_contact" validate:"omitempty,iq_phone"`
+	DoctorID             *int64    `json:"doctor_id,string" validate:"omitempty,min=1"`
+	AppointmentTime      time.Time `json:"appointment_time" validate:"required,gt"`
+	ReasonForAppointment *string   `json:"reason_for_appointment" validate:"omitempty,max=2000"`
+}
+
+// Appointment returns the appointment to book.
+func (r AppointmentRequest) Appointment() models.FacilityAppointment {
+	return models.FacilityAppointment{
+		PatientName:          r.PatientName,
+		PatientContact:       r.PatientContact,
+		DoctorID:             r.DoctorID,
+		AppointmentTime:      r.AppointmentTime,
+		ReasonForAppointment: r.ReasonForAppointment,
+	}
+}
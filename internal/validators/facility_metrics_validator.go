@@ -0,0 +1,33 @@
+package validators
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+// FacilityMetricsRequest is the body of a batch of facility metric samples.
+type FacilityMetricsRequest struct {
+	Metrics []FacilityMetricSample `json:"metrics" validate:"required,min=1,max=1000,dive"`
+}
+
+// FacilityMetricSample is a single sample of a facility metric. Values are never negative; bed
+// occupancy is a percentage.
+type FacilityMetricSample struct {
+	MetricType  string    `json:"metric_type" validate:"required,facility_metric_type"`
+	MetricValue *float64  `json:"metric_value" validate:"required,min=0"`
+	MeasuredAt  time.Time `json:"measured_at" validate:"required"`
+}
+
+// Samples returns the samples to store.
+func (r FacilityMetricsRequest) Samples() []models.FacilityMetrics {
+	samples := make([]models.FacilityMetrics, len(r.Metrics))
+	for i, sample := range r.Metrics {
+		samples[i] = models.FacilityMetrics{
+			MetricType:  models.FacilityMetricType(sample.MetricType),
+			MetricValue: *sample.MetricValue,
+			MeasuredAt:  sample.MeasuredAt,
+		}
+	}
+	return samples
+}
@@ -1,21 +1,125 @@
 package validators
 
 import (
-	"server/pkg/utils"
+	"time"
 
-	"github.com/gin-gonic/gin"
+	"server/internal/models"
 )
 
-// FacilityRequest represents the structure of the request body for creating or updating a facility.
+// FacilityRequest represents the structure of the request body for updating a facility.
 type FacilityRequest struct {
 	Name        string `json:"name" validate:"required"`
-	Description string `json:"description" validate:"required"`
-	Type        string `json:"type" validate:"required"`
-	CityID      int    `json:"city_id" validate:"required"`
+	Description string `json:"description" validate:"required" sanitize:"ugc"`
+	Type        string `json:"type" validate:"required,facility_type"`
+	CityID      int    `json:"city_id,string" validate:"required"`
 }
 
-// ValidateFacilityRequest validates the request body for creating or updating a facility.
-func ValidateFacilityRequest(c *gin.Context, facilityRequest FacilityRequest) {
-	// Use the ValidateRequest middleware to validate the request
-	utils.ValidateRequest(c, facilityRequest)
+// CreateFacilityRequest represents the request body for creating a facility together with its
+// weekly operating hours.
+type CreateFacilityRequest struct {
+	FacilityRequest
+	Location       string                  `json:"location" validate:"required"`
+	Coordinates    *string                 `json:"coordinates" validate:"omitempty,coordinates"`
+	Phone          *string                 `json:"phone" validate:"omitempty,iq_phone"`
+	EmergencyPhone *string                 `json:"emergency_phone" validate:"omitempty,iq_phone"`
+	OperatingHours []OperatingHoursRequest `json:"operating_hours" validate:"omitempty,dive"`
+}
+
+// OperatingHoursRequest describes a facility's hours on one day of the week (0=Sunday).
+// Times are HH:MM; a day that is not closed must close at a different time than it opens, and
+// closing earlier than it opens means closing the next day.
+type OperatingHoursRequest struct {
+	DayOfWeek *int   `json:"day_of_week" validate:"required,min=0,max=6"`
+	StartTime string `json:"start_time" validate:"required_unless=IsClosed true,omitempty,time_of_day"`
+	EndTime   string `json:"end_time" validate:"required_unless=IsClosed true,omitempty,time_of_day,time_after=start_time"`
+	IsClosed  bool   `json:"is_closed"`
+}
+
+// FacilityPatchRequest represents a partial facility update; only the fields present are changed.
+type FacilityPatchRequest struct {
+	Name           *string `json:"name" validate:"omitempty,min=1"`
+	Description    *string `json:"description" sanitize:"ugc"`
+	Type           *string `json:"type" validate:"omitempty,facility_type"`
+	CityID         *int    `json:"city_id,string" validate:"omitempty,min=1"`
+	Coordinates    *string `json:"coordinates" validate:"omitempty,coordinates"`
+	Phone          *string `json:"phone" validate:"omitempty,iq_phone"`
+	EmergencyPhone *string `json:"emergency_phone" validate:"omitempty,iq_phone"`
+}
+
+// Updates returns the columns to set for the fields present in the request.
+func (r FacilityPatchRequest) Updates() map[string]interface{} {
+	updates := map[string]interface{}{}
+	if r.Name != nil {
+		updates["name"] = *r.Name
+	}
+	if r.Description != nil {
+		updates["description"] = *r.Description
+	}
+	if r.Type != nil {
+		updates["type"] = *r.Type
+	}
+	if r.CityID != nil {
+		updates["city_id"] = *r.CityID
+	}
+	if r.Coordinates != nil {
+		updates["coordinates"] = *r.Coordinates
+	}
+	if r.Phone != nil {
+		updates["phone"] = *r.Phone
+	}
+	if r.EmergencyPhone != nil {
+		updates["emergency_phone"] = *r.EmergencyPhone
+	}
+	return updates
+}
+
+// Updates returns the columns replaced by a full facility update.
+func (r FacilityRequest) Updates() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        r.Name,
+		"description": r.Description,
+		"type":        r.Type,
+		"city_id":     r.CityID,
+	}
+}
+
+// Details returns the facility and operating hours to create. The request must have been validated.
+func (r CreateFacilityRequest) Details() models.FacilityDetails {
+	cityID := int64(r.CityID)
+	description := r.Description
+	details := models.FacilityDetails{
+		Facility: models.Facility{
+			Name:           r.Name,
+			Type:           models.FacilityType(r.Type),
+			CityID:         &cityID,
+			Location:       r.Location,
+			Coordinates:    r.Coordinates,
+			Phone:          r.Phone,
+			EmergencyPhone: r.EmergencyPhone,
+			Description:    &description,
+		},
+		OperatingHours: make([]models.FacilityOperatingHours, len(r.OperatingHours)),
+	}
+	for i, hours := range r.OperatingHours {
+		details.OperatingHours[i] = hours.model()
+	}
+	return details
+}
+
+func (r OperatingHoursRequest) model() models.FacilityOperatingHours {
+	hours := models.FacilityOperatingHours{DayOfWeek: *r.DayOfWeek, IsClosed: r.IsClosed}
+	if start, ok := parseTimeOfDay(r.StartTime); ok {
+		hours.StartTime = timeOfDay(start)
+	}
+	if end, ok := parseTimeOfDay(r.EndTime); ok {
+		hours.EndTime = timeOfDay(end)
+	}
+	return hours
+}
+
+// timeOfDay moves a parsed wall-clock time onto a fixed date that the TIME columns ignore;
+// time.Parse's year 0 would be encoded as a BC date.
+func timeOfDay(t time.Time) *time.Time {
+	onDate := time.Date(2000, time.January, 1, t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return &onDate
 }
@@ -0,0 +1,8 @@
+package validators
+
+// ReviewRequest is the body of a review. The comment may carry basic formatting such as emphasis
+// and links; anything else is stripped when request bodies are sanitized.
+type ReviewRequest struct {
+	Rating  *float64 `json:"rating" validate:"required,min=1,max=5"`
+	Comment *string  `json:"comment" validate:"omitempty,max=2000" sanitize:"ugc"`
+}
@@ -0,0 +1,116 @@
+package validators
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// Embeds the time zone database so iana_timezone works on images without one.
+	_ "time/tzdata"
+
+	"server/internal/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// iraqiMobile matches Iraqi mobile numbers in local (07XX) or international (+964 7XX) form.
+var iraqiMobile = regexp.MustCompile(`^(?:\+964|00964|0)7[3-9]\d{8}$`)
+
+func isIraqiPhone(fl validator.FieldLevel) bool {
+	return iraqiMobile.MatchString(fl.Field().String())
+}
+
+func isFacilityType(fl validator.FieldLevel) bool {
+	return models.FacilityType(fl.Field().String()).Valid()
+}
+
+func facilityTypeList() string {
+	names := make([]string, len(models.FacilityTypes))
+	for i, facilityType := range models.FacilityTypes {
+		names[i] = string(facilityType)
+	}
+	return strings.Join(names, ", ")
+}
+
+func isFacilityMetricType(fl validator.FieldLevel) bool {
+	return models.FacilityMetricType(fl.Field().String()).Valid()
+}
+
+func facilityMetricTypeList() string {
+	names := make([]string, len(models.FacilityMetricTypes))
+	for i, metricType := range models.FacilityMetricTypes {
+		names[i] = string(metricType)
+	}
+	return strings.Join(names, ", ")
+}
+
+// isCoordinates accepts "latitude,longitude", optionally in parentheses as Postgres prints points.
+func isCoordinates(fl validator.FieldLevel) bool {
+	value := strings.TrimSuffix(strings.TrimPrefix(fl.Field().String(), "("), ")")
+	lat, lng, ok := strings.Cut(value, ",")
+	if !ok {
+		return false
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return false
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	return err == nil && longitude >= -180 && longitude <= 180
+}
+
+// isIANATimeZone accepts names from the IANA time zone database. Unlike time.LoadLocation it
+// rejects "" and "Local", which name the server's zone rather than a real one.
+func isIANATimeZone(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// parseTimeOfDay parses a wall-clock time written as HH:MM or HH:MM:SS.
+func parseTimeOfDay(value string) (time.Time, bool) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isTimeOfDay(fl validator.FieldLevel) bool {
+	_, ok := parseTimeOfDay(fl.Field().String())
+	return ok
+}
+
+// isTimeAfter checks a time of day can follow the sibling field whose JSON name is the tag's
+// parameter, e.g. `validate:"time_after=start_time"`. An earlier time is read as the next day, so
+// overnight ranges such as 22:00-06:00 pass and only equal times are rejected. A sibling that is
+// empty or malformed is left to its own rules.
+func isTimeAfter(fl validator.FieldLevel) bool {
+	end, ok := parseTimeOfDay(fl.Field().String())
+	if !ok {
+		return true
+	}
+	sibling, found := fieldByJSONName(reflect.Indirect(fl.Parent()), fl.Param())
+	if !found {
+		return false
+	}
+	start, ok := parseTimeOfDay(reflect.Indirect(sibling).String())
+	return !ok || !end.Equal(start)
+}
+
+func fieldByJSONName(parent reflect.Value, name string) (reflect.Value, bool) {
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < parent.NumField(); i++ {
+		if jsonFieldName(parent.Type().Field(i)) == name {
+			return parent.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,233 @@
+package validators
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/ar"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	ar_translations "github.com/go-playground/validator/v10/translations/ar"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+)
+
+// validate checks request structs against their `validate` tags. It replaces gin's default
+// validator, so c.ShouldBindJSON decodes and validates a body in one step.
+var validate = validator.New()
+
+// translators holds the locales error messages are available in. English is the fallback.
+var translators = ut.New(en.New(), en.New(), ar.New())
+
+// messages are the translations of the custom tags and of the messages surrounding field errors.
+// {0} is the field's JSON name and {1} the tag's parameter.
+var messages = map[string]map[string]string{
+	"en": {
+		"iq_phone":             "{0} must be an Iraqi mobile number such as 07701234567 or +9647701234567",
+		"facility_type":        "{0} must be one of: {1}",
+		"facility_metric_type": "{0} must be one of: {1}",
+		"coordinates":          "{0} must be a \"latitude,longitude\" pair within valid ranges",
+		"iana_timezone":        "{0} must be an IANA time zone such as Asia/Baghdad",
+		"time_of_day":          "{0} must be a time of day in HH:MM format",
+		"time_after":           "{0} must differ from {1}",
+
+		"invalid_request": "Invalid request data",
+		"invalid_body":    "Invalid request body",
+	},
+	"ar": {
+		"iq_phone":             "{0} يجب أن يكون رقم هاتف محمول عراقي مثل 07701234567 أو 9647701234567+",
+		"facility_type":        "{0} يجب أن يكون أحد الأنواع التالية: {1}",
+		"facility_metric_type": "{0} يجب أن يكون أحد المقاييس التالية: {1}",
+		"coordinates":          "{0} يجب أن يكون بالصيغة \"خط العرض,خط الطول\" ضمن النطاقات الصحيحة",
+		"iana_timezone":        "{0} يجب أن يكون منطقة زمنية من IANA مثل Asia/Baghdad",
+		"time_of_day":          "{0} يجب أن يكون وقتاً بالصيغة HH:MM",
+		"time_after":           "{0} يجب أن يختلف عن {1}",
+
+		// The stock Arabic translations have no conditional required tags.
+		"required_unless":  "حقل {0} مطلوب",
+		"required_without": "حقل {0} مطلوب",
+
+		"invalid_request": "بيانات الطلب غير صالحة",
+		"invalid_body":    "نص الطلب غير صالح",
+	},
+}
+
+// customValidations are the tags this package adds to the validator's built-in ones.
+var customValidations = map[string]validator.Func{
+	"iq_phone":             isIraqiPhone,
+	"facility_type":        isFacilityType,
+	"facility_metric_type": isFacilityMetricType,
+	"coordinates":          isCoordinates,
+	"iana_timezone":        isIANATimeZone,
+	"time_of_day":          isTimeOfDay,
+	"time_after":           isTimeAfter,
+}
+
+func init() {
+	validate.RegisterTagNameFunc(jsonFieldName)
+	for tag, fn := range customValidations {
+		mustRegister(validate.RegisterValidation(tag, fn))
+	}
+
+	enTrans, _ := translators.GetTranslator("en")
+	arTrans, _ := translators.GetTranslator("ar")
+	mustRegister(en_translations.RegisterDefaultTranslations(validate, enTrans))
+	mustRegister(ar_translations.RegisterDefaultTranslations(validate, arTrans))
+	for _, trans := range []ut.Translator{enTrans, arTrans} {
+		for key, text := range messages[trans.Locale()] {
+			if _, isTag := customValidations[key]; isTag || strings.HasPrefix(key, "required_") {
+				mustRegister(validate.RegisterTranslation(key, trans, registerMessage(key, text), translateFieldError))
+			} else {
+				mustRegister(trans.Add(key, text, false))
+			}
+		}
+	}
+
+	binding.Validator = structValidator{}
+}
+
+func mustRegister(err error) {
+	if err != nil {
+		panic("validators: " + err.Error())
+	}
+}
+
+func registerMessage(key, text string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(key, text, true)
+	}
+}
+
+func translateFieldError(trans ut.Translator, fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "facility_type":
+		param = facilityTypeList()
+	case "facility_metric_type":
+		param = facilityMetricTypeList()
+	}
+	message, err := trans.T(fe.Tag(), fe.Field(), param)
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// jsonFieldName names fields after their JSON keys, so errors refer to what clients sent.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// structValidator adapts validate to gin's binding.StructValidator.
+type structValidator struct{}
+
+func (v structValidator) ValidateStruct(obj any) error {
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return v.ValidateStruct(value.Elem().Interface())
+	case reflect.Struct:
+		return validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (structValidator) Engine() any { return validate }
+
+// Translator returns the translator for the best supported language of an Accept-Language header.
+func Translator(acceptLanguage string) ut.Translator {
+	trans, _ := translators.FindTranslator(preferredLanguages(acceptLanguage)...)
+	return trans
+}
+
+// preferredLanguages lists the primary language subtags of an Accept-Language header, most
+// preferred first.
+func preferredLanguages(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag, quality})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+
+	tags := make([]string, len(languages))
+	for i, lang := range languages {
+		tags[i] = lang.tag
+	}
+	return tags
+}
+
+// BindError classifies an error from binding a request body as a validation error. Field errors
+// are listed individually, translated into the language of the Accept-Language header.
+func BindError(err error, acceptLanguage string) error {
+	trans := Translator(acceptLanguage)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return apperrors.Wrap(err, apperrors.KindValidation, message(trans, "invalid_body"))
+	}
+
+	fields := make([]apperrors.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, apperrors.FieldError{Field: fieldPath(fieldErr), Message: fieldErr.Translate(trans)})
+	}
+	return apperrors.Validation(message(trans, "invalid_request"), fields...)
+}
+
+func message(trans ut.Translator, key string) string {
+	text, err := trans.T(key)
+	if err != nil {
+		return messages["en"][key]
+	}
+	return text
+}
+
+// fieldPath is the location of the field in the request body, such as operating_hours[0].end_time.
+// Go names left in the namespace belong to the root and embedded structs, which have no JSON key.
+func fieldPath(fe validator.FieldError) string {
+	segments := strings.Split(fe.Namespace(), ".")
+	path := segments[:0]
+	for _, segment := range segments {
+		if segment != "" && segment[0] >= 'A' && segment[0] <= 'Z' {
+			continue
+		}
+		path = append(path, segment)
+	}
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,105 @@
+// Package apperrors defines the domain errors services return and how they map onto HTTP.
+// Handlers pass errors on unchanged; middlewares.ErrorHandler renders them as RFC 7807 problems.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Kind classifies a domain error. Its value is exposed to clients as the problem's code.
+type Kind string
+
+const (
+	KindInternal             Kind = "internal"
+	KindNotFound             Kind = "not_found"
+	KindConflict             Kind = "conflict"
+	KindValidation           Kind = "validation_failed"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindRateLimited          Kind = "rate_limited"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindPreconditionRequired Kind = "precondition_required"
+)
+
+// statuses maps each kind to the HTTP status it is answered with.
+var statuses = map[Kind]int{
+	KindInternal:             http.StatusInternalServerError,
+	KindNotFound:             http.StatusNotFound,
+	KindConflict:             http.StatusConflict,
+	KindValidation:           http.StatusBadRequest,
+	KindUnauthorized:         http.StatusUnauthorized,
+	KindForbidden:            http.StatusForbidden,
+	KindRateLimited:          http.StatusTooManyRequests,
+	KindPreconditionFailed:   http.StatusPreconditionFailed,
+	KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// Status returns the HTTP status of kind.
+func (k Kind) Status() int {
+	if status, ok := statuses[k]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError describes why a single request field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error. Message is safe to show to clients; the wrapped Err is not.
+type Error struct {
+	Kind    Kind
+	Message string
+	// Fields lists the invalid fields of a validation error.
+	Fields []FieldError
+	// RetryAfter tells rate-limited clients when to try again.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns an error of the given kind.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap classifies err as kind, keeping it as the cause. errors.Is and errors.As still see err.
+func Wrap(err error, kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func NotFound(message string) *Error     { return New(KindNotFound, message) }
+func Conflict(message string) *Error     { return New(KindConflict, message) }
+func Unauthorized(message string) *Error { return New(KindUnauthorized, message) }
+func Forbidden(message string) *Error    { return New(KindForbidden, message) }
+
+// Validation returns a validation error, optionally naming the offending fields.
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+// RateLimited returns an error telling the client to retry after the given delay.
+func RateLimited(message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindRateLimited, Message: message, RetryAfter: retryAfter}
+}
+
+// KindOf returns the kind of the first Error in err's chain, or KindInternal when there is none.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
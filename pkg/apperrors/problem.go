@@ -0,0 +1,55 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest is the non-standard status recorded when the client disconnects
+// before a response is written, following the nginx convention.
+const StatusClientClosedRequest = 499
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code and the fields after it are extensions.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Code      Kind         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// internalDetail replaces the message of unclassified errors, which may leak implementation details.
+const internalDetail = "An unexpected error occurred"
+
+// ProblemFor describes err as a problem for the request at instance.
+// Errors without a Kind are internal errors and their message is not exposed.
+func ProblemFor(err error, instance string) Problem {
+	problem := Problem{Type: "about:blank", Instance: instance}
+
+	var appErr *Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		problem.Status, problem.Code, problem.Detail = http.StatusGatewayTimeout, "timeout", "Request timed out"
+	case errors.Is(err, context.Canceled):
+		problem.Status, problem.Code, problem.Detail = StatusClientClosedRequest, "client_closed_request", "Client closed the request"
+	case errors.As(err, &appErr) && appErr.Kind != KindInternal:
+		problem.Status, problem.Code, problem.Detail = appErr.Kind.Status(), appErr.Kind, appErr.Message
+		problem.Errors = appErr.Fields
+	default:
+		problem.Status, problem.Code, problem.Detail = http.StatusInternalServerError, KindInternal, internalDetail
+	}
+
+	problem.Title = http.StatusText(problem.Status)
+	if problem.Title == "" {
+		problem.Title = "Client Closed Request"
+	}
+	return problem
+}
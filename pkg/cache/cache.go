@@ -0,0 +1,329 @@
+// Package cache is a two-tier read-through cache: a bounded in-process LRU in front of Redis.
+//
+// Values are loaded once per key at a time, however many requests miss together, and stored in
+// both tiers. Invalidations drop entries from both tiers and are broadcast over Redis pub/sub, so
+// every instance running Run drops its local copy too. Without Redis the cache is local only.
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/singleflight"
+)
+
+// Options sizes a cache and bounds how long entries live in each tier.
+type Options struct {
+	// LocalSize is the number of entries kept in process.
+	LocalSize int
+	// LocalTTL is how long an entry is kept in process. It also bounds how stale an instance can
+	// serve an entry when it misses an invalidation, for example while disconnected from Redis.
+	LocalTTL time.Duration
+	// TTL is how long an entry is kept in Redis.
+	TTL time.Duration
+	// LoadTimeout bounds a load shared by concurrent misses. Zero uses defaultLoadTimeout.
+	LoadTimeout time.Duration
+}
+
+// defaultLoadTimeout bounds shared loads of caches whose options do not set LoadTimeout.
+const defaultLoadTimeout = 10 * time.Second
+
+// Cache caches values of type T by string key. T must survive a JSON round trip, the form values
+// are stored in Redis.
+type Cache[T any] struct {
+	name    string
+	opts    Options
+	redis   *redis.Client
+	origin  string // tells this instance's broadcasts apart from those of other instances
+	local   *expirable.LRU[string, T]
+	flights singleflight.Group
+
+	// epoch is bumped by every invalidation. A load that straddles one is not stored, so an
+	// invalidation cannot be undone by a read that started before it.
+	mu    sync.Mutex
+	epoch uint64
+}
+
+// New creates the cache called name, which labels its metrics and prefixes its Redis keys.
+// client may be nil to cache in process only.
+func New[T any](name string, client *redis.Client, opts Options) *Cache[T] {
+	origin := make([]byte, 8)
+	_, _ = rand.Read(origin)
+
+	return &Cache[T]{
+		name:   name,
+		opts:   opts,
+		redis:  client,
+		origin: hex.EncodeToString(origin),
+		local:  expirable.NewLRU[string, T](opts.LocalSize, nil, opts.LocalTTL),
+	}
+}
+
+// Get returns the value cached under key, calling load to produce it on a miss. Concurrent misses
+// of the same key share a single call. Errors of load are returned and not cached; Redis errors
+// are counted and the lookup carries on as if Redis had missed.
+//
+// The shared call is detached from the cancellation of the caller that started it and bounded by
+// LoadTimeout instead, so one caller giving up does not fail the others. Each caller still stops
+// waiting when its own ctx is done.
+func (c *Cache[T]) Get(ctx context.Context, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if value, ok := c.local.Get(key); ok {
+		hitsTotal.WithLabelValues(c.name, "local").Inc()
+		return value, nil
+	}
+
+	flight := c.flights.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loadTimeout())
+		defer cancel()
+		return c.fetch(loadCtx, key, load)
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-flight:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
+
+func (c *Cache[T]) loadTimeout() time.Duration {
+	if c.opts.LoadTimeout > 0 {
+		return c.opts.LoadTimeout
+	}
+	return defaultLoadTimeout
+}
+
+// fetch looks key up in Redis and, failing that, loads it and stores it in both tiers.
+func (c *Cache[T]) fetch(ctx context.Context, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	epoch := c.currentEpoch()
+
+	if value, ok := c.getRemote(ctx, key); ok {
+		hitsTotal.WithLabelValues(c.name, "redis").Inc()
+		c.storeLocal(epoch, key, value)
+		return value, nil
+	}
+
+	missesTotal.WithLabelValues(c.name).Inc()
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	if c.storeLocal(epoch, key, value) {
+		c.setRemote(ctx, key, value)
+		// An invalidation that ran while the value was written to Redis may have deleted the key
+		// before it was written; delete it again rather than leave a stale value for a whole TTL
+		if c.currentEpoch() != epoch {
+			c.deleteRemote(ctx, []string{c.redisKey(key)})
+		}
+	}
+	return value, nil
+}
+
+// Invalidate drops the entries of keys from both tiers and tells the other instances to drop them.
+func (c *Cache[T]) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	c.dropLocal(keys)
+	invalidationsTotal.WithLabelValues(c.name, "local").Add(float64(len(keys)))
+
+	if c.redis == nil {
+		return nil
+	}
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = c.redisKey(key)
+	}
+	return errors.Join(c.deleteRemote(ctx, redisKeys), c.publish(ctx, keys))
+}
+
+// InvalidateAll drops every entry from both tiers and tells the other instances to do the same.
+func (c *Cache[T]) InvalidateAll(ctx context.Context) error {
+	c.dropLocal(nil)
+	invalidationsTotal.WithLabelValues(c.name, "local").Inc()
+
+	if c.redis == nil {
+		return nil
+	}
+
+	var keys []string
+	iter := c.redis.Scan(ctx, 0, c.redisKey("*"), 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		errorsTotal.WithLabelValues(c.name, "scan").Inc()
+		return errors.Join(err, c.publish(ctx, nil))
+	}
+	return errors.Join(c.deleteRemote(ctx, keys), c.publish(ctx, nil))
+}
+
+// invalidation is the message broadcast on a cache's channel. No keys means every key.
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+}
+
+// Run applies the invalidations broadcast by other instances until ctx is cancelled. It returns
+// at once when the cache has no Redis client.
+//
+// The local tier is emptied whenever the subscription is (re)established, since broadcasts sent
+// while it was down are lost.
+func (c *Cache[T]) Run(ctx context.Context) {
+	if c.redis == nil {
+		return
+	}
+
+	sub := c.redis.Subscribe(ctx, c.channel())
+	defer sub.Close()
+
+	messages := sub.ChannelWithSubscriptions(ctx, 100)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			switch msg := msg.(type) {
+			case *redis.Subscription:
+				if msg.Kind == "subscribe" {
+					c.dropLocal(nil)
+				}
+			case *redis.Message:
+				c.applyBroadcast(msg.Payload)
+			}
+		}
+	}
+}
+
+func (c *Cache[T]) applyBroadcast(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		errorsTotal.WithLabelValues(c.name, "decode").Inc()
+		return
+	}
+	if msg.Origin == c.origin {
+		return
+	}
+
+	c.dropLocal(msg.Keys)
+	count := len(msg.Keys)
+	if count == 0 {
+		count = 1
+	}
+	invalidationsTotal.WithLabelValues(c.name, "remote").Add(float64(count))
+}
+
+func (c *Cache[T]) currentEpoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch
+}
+
+// storeLocal caches value in process unless an invalidation ran since epoch, and reports whether
+// it did.
+func (c *Cache[T]) storeLocal(epoch uint64, key string, value T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.epoch != epoch {
+		return false
+	}
+	c.local.Add(key, value)
+	return true
+}
+
+// dropLocal removes keys from the local tier, or every key when keys is nil, and makes later
+// lookups start fresh loads instead of joining ones already in flight.
+func (c *Cache[T]) dropLocal(keys []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	if keys == nil {
+		c.local.Purge()
+		return
+	}
+	for _, key := range keys {
+		c.local.Remove(key)
+		c.flights.Forget(key)
+	}
+}
+
+func (c *Cache[T]) getRemote(ctx context.Context, key string) (T, bool) {
+	var value T
+	if c.redis == nil {
+		return value, false
+	}
+
+	data, err := c.redis.Get(ctx, c.redisKey(key)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			errorsTotal.WithLabelValues(c.name, "get").Inc()
+		}
+		return value, false
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		errorsTotal.WithLabelValues(c.name, "decode").Inc()
+		return value, false
+	}
+	return value, true
+}
+
+func (c *Cache[T]) setRemote(ctx context.Context, key string, value T) {
+	if c.redis == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		errorsTotal.WithLabelValues(c.name, "encode").Inc()
+		return
+	}
+	if err := c.redis.Set(ctx, c.redisKey(key), data, c.opts.TTL).Err(); err != nil {
+		errorsTotal.WithLabelValues(c.name, "set").Inc()
+	}
+}
+
+func (c *Cache[T]) deleteRemote(ctx context.Context, redisKeys []string) error {
+	if c.redis == nil || len(redisKeys) == 0 {
+		return nil
+	}
+	if err := c.redis.Del(ctx, redisKeys...).Err(); err != nil {
+		errorsTotal.WithLabelValues(c.name, "delete").Inc()
+		return err
+	}
+	return nil
+}
+
+func (c *Cache[T]) publish(ctx context.Context, keys []string) error {
+	payload, err := json.Marshal(invalidation{Origin: c.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	if err := c.redis.Publish(ctx, c.channel(), payload).Err(); err != nil {
+		errorsTotal.WithLabelValues(c.name, "publish").Inc()
+		return err
+	}
+	return nil
+}
+
+func (c *Cache[T]) redisKey(key string) string {
+	return "cache:" + c.name + ":" + key
+}
+
+func (c *Cache[T]) channel() string {
+	return "cache:invalidate:" + c.name
+}
@@ -0,0 +1,46 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// hitsTotal counts lookups answered by a tier: local or redis.
+	hitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Total number of cache lookups answered without loading, by cache and tier",
+		},
+		[]string{"cache", "tier"},
+	)
+
+	// missesTotal counts lookups that had to load the value.
+	missesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Total number of cache lookups that loaded the value from the source",
+		},
+		[]string{"cache"},
+	)
+
+	// invalidationsTotal counts dropped entries by where the invalidation came from: local for
+	// writes made by this instance, remote for ones broadcast by another.
+	invalidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_total",
+			Help: "Total number of cache invalidations by cache and source",
+		},
+		[]string{"cache", "source"},
+	)
+
+	// errorsTotal counts failed Redis operations; the cache degrades to the tiers that still work.
+	errorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_errors_total",
+			Help: "Total number of failed cache operations by cache and operation",
+		},
+		[]string{"cache", "operation"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(hitsTotal, missesTotal, invalidationsTotal, errorsTotal)
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping checks that a dependency answers ping, such as (*sqlx.DB).PingContext.
+func Ping(ping func(ctx context.Context) error) Check {
+	return func(ctx context.Context) (string, error) {
+		return "", ping(ctx)
+	}
+}
+
+// SchemaVersioner reports the applied and expected schema versions; *migrate.Migrator implements it.
+type SchemaVersioner interface {
+	Version(ctx context.Context) (int64, error)
+	Latest() int64
+}
+
+// MigrationVersion checks that every migration this binary knows about has been applied, so
+// the queries it runs match the schema.
+func MigrationVersion(migrator SchemaVersioner) Check {
+	return func(ctx context.Context) (string, error) {
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return "", err
+		}
+		if latest := migrator.Latest(); version < latest {
+			return "", fmt.Errorf("schema is at version %d, expected %d", version, latest)
+		}
+		return fmt.Sprintf("version %d", version), nil
+	}
+}
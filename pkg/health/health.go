@@ -0,0 +1,125 @@
+// Package health reports whether the server and the dependencies it needs are usable, for the
+// liveness and readiness probes of the orchestrator.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a report and of its individual checks.
+const (
+	StatusOK           = "ok"
+	StatusFailed       = "failed"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check probes a dependency. It returns a short detail to show on success, such as a version,
+// and must give up when ctx is done.
+type Check func(ctx context.Context) (detail string, err error)
+
+// Result is the outcome of one check.
+type Result struct {
+	Status     string  `json:"status"`
+	Detail     string  `json:"detail,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Report is the outcome of all checks. Ready is false when any check failed or the server is
+// shutting down.
+type Report struct {
+	Ready  bool              `json:"-"`
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks of the server's dependencies.
+type Checker struct {
+	timeout      time.Duration
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+// NewChecker creates a Checker that gives each check at most timeout to answer.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check under name. Checks must be added before the checker is used.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// ShutDown makes every following report not ready, so load balancers stop routing new requests
+// here while in-flight ones drain.
+func (c *Checker) ShutDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Check runs all checks concurrently, each bounded by the checker's timeout.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Ready: true, Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check namedCheck) {
+			defer wg.Done()
+			result := c.run(ctx, check.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.name] = result
+			if result.Status != StatusOK {
+				report.Ready, report.Status = false, StatusUnavailable
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	if c.shuttingDown.Load() {
+		report.Ready, report.Status = false, StatusShuttingDown
+	}
+	return report
+}
+
+// run runs one check. A check that outlives its timeout is abandoned and reported as failed.
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+
+	start := time.Now()
+	go func() {
+		detail, err := check(ctx)
+		done <- outcome{detail, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+
+	result := Result{Status: StatusOK, Detail: out.detail, DurationMs: float64(time.Since(start).Microseconds()) / 1000}
+	if out.err != nil {
+		result.Status, result.Detail, result.Error = StatusFailed, "", out.err.Error()
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx whose logger adds fields to every entry, on top of the fields of
+// the logger ctx already carries.
+func NewContext(ctx context.Context, fields ...zap.Field) context.Context {
+	return context.WithValue(ctx, contextKey{}, contextLogger(ctx).With(fields...))
+}
+
+// FromContext returns the logger of ctx, which for a request carries its request ID, so services
+// and repositories log lines that can be correlated with the request. Without one it returns the
+// global logger. When ctx carries a span, entries also carry its trace_id and span_id, which link
+// them to the trace.
+func FromContext(ctx context.Context) *zap.Logger {
+	l := contextLogger(ctx)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		l = l.With(zap.String("trace_id", span.TraceID().String()), zap.String("span_id", span.SpanID().String()))
+	}
+	return l
+}
+
+func contextLogger(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return logger
+}
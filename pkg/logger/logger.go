@@ -1,3 +1,6 @@
+// Package logger provides the server's structured logger. Request-scoped loggers carrying the
+// request ID travel in the context (see NewContext and FromContext), and fields holding personal
+// data or credentials are redacted before they are written (see Redact).
 package logger
 
 import (
@@ -5,7 +8,8 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var logger *zap.Logger
+// logger discards everything until InitLogger is called, so packages can log in tests.
+var logger = zap.NewNop()
 
 func InitLogger(level string) {
 	var zapConfig zap.Config
@@ -18,12 +22,18 @@ func InitLogger(level string) {
 	zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
 	var err error
-	logger, err = zapConfig.Build()
+	logger, err = zapConfig.Build(zap.WrapCore(Redact))
 	if err != nil {
 		panic("Failed to initialize logger: " + err.Error())
 	}
 }
 
+// SetLogger replaces the logger, for example with an observer in tests. Wrap its core with Redact
+// to keep personal data out of the logs.
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
 func Info(msg string, fields ...zap.Field) {
 	logger.Info(msg, fields...)
 }
@@ -32,6 +42,10 @@ func Debug(msg string, fields ...zap.Field) {
 	logger.Debug(msg, fields...)
 }
 
+func Warn(msg string, fields ...zap.Field) {
+	logger.Warn(msg, fields...)
+}
+
 func Error(msg string, fields ...zap.Field) {
 	logger.Error(msg, fields...)
 }
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the value of a redacted field.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of field names that mark a field as personal data or a credential.
+var sensitiveKeys = []string{
+	"password", "secret", "token", "authorization", "cookie", "api_key", "apikey",
+	"email", "phone", "national_id",
+}
+
+// sensitiveValues match personal data wherever it turns up in text: email addresses, Iraqi mobile
+// numbers and the values PostgreSQL quotes in constraint errors, e.g. "Key (email)=(ali@example.com)".
+var sensitiveValues = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`Key \(([^)]*)\)=\((?:[^()]|\([^()]*\))*\)`), "Key ($1)=(" + Redacted + ")"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), Redacted},
+	{regexp.MustCompile(`(?:\+964|\b964|\b0)7\d{9}\b`), Redacted},
+}
+
+// Redact wraps core so the values of fields whose names contain a sensitive word, such as
+// "email", "phone" or "token", are written as [REDACTED]. Names are matched case-insensitively,
+// so "user_email" and "sessionToken" are redacted too.
+//
+// Personal data in the message, in string and error values, and in values logged with zap.Any,
+// such as the email quoted by a unique violation, is replaced with [REDACTED] as well.
+func Redact(core zapcore.Core) zapcore.Core {
+	return redactingCore{core}
+}
+
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{c.Core.With(redact(fields))}
+}
+
+func (c redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = scrub(entry.Message)
+	return c.Core.Write(entry, redact(fields))
+}
+
+// redact returns fields with sensitive values replaced, copying the slice only when needed.
+func redact(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		replacement, ok := redactField(field)
+		if !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+		redacted[i] = replacement
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactField returns the field with its sensitive value replaced, and whether it had one.
+func redactField(field zapcore.Field) (zapcore.Field, bool) {
+	switch {
+	case sensitive(field.Key):
+		return zap.String(field.Key, Redacted), true
+	case field.Type == zapcore.StringType:
+		if scrubbed := scrub(field.String); scrubbed != field.String {
+			return zap.String(field.Key, scrubbed), true
+		}
+	case field.Type == zapcore.ErrorType:
+		// The error's type and verbose form are dropped along with the personal data
+		if err, ok := field.Interface.(error); ok {
+			if message, scrubbed := err.Error(), scrub(err.Error()); scrubbed != message {
+				return zap.String(field.Key, scrubbed), true
+			}
+		}
+	case field.Type == zapcore.ReflectType:
+		if value, ok := redactValue(field.Interface); ok {
+			return zap.Any(field.Key, value), true
+		}
+	}
+	return field, false
+}
+
+// redactValue redacts a value logged with zap.Any through its JSON form, the form it is logged in.
+// It reports false when there was nothing to redact.
+func redactValue(value any) (any, bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, false
+	}
+
+	changed := false
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, item := range v {
+				if sensitive(key) {
+					v[key], changed = Redacted, true
+					continue
+				}
+				v[key] = walk(item)
+			}
+		case []any:
+			for i, item := range v {
+				v[i] = walk(item)
+			}
+		case string:
+			if scrubbed := scrub(v); scrubbed != v {
+				changed = true
+				return scrubbed
+			}
+		}
+		return v
+	}
+	document = walk(document)
+	return document, changed
+}
+
+// scrub replaces the personal data found in text.
+func scrub(text string) string {
+	for _, value := range sensitiveValues {
+		text = value.pattern.ReplaceAllString(text, value.replacement)
+	}
+	return text
+}
+
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range sensitiveKeys {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,14 @@
 package middlewares
 
 import (
-	"net/http"
+	"slices"
+
 	"server/internal/services"
-	"server/pkg/utils"
+	"server/pkg/apperrors"
+	"server/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // AuthMiddleware checks if the user has a valid authorization token
@@ -14,33 +17,35 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		// Extract token from headers or query params
 		token := c.GetHeader("Authorization")
 		if token == "" {
-			c.JSON(401, gin.H{"error": "Authorization token required"})
+			_ = c.Error(apperrors.Unauthorized("Authorization token required"))
 			c.Abort()
 			return
 		}
 
 		// Validate the token (you can implement more specific logic here)
-		_, err := authService.ValidateAuthToken(token)
+		claims, err := authService.ValidateAuthToken(token)
 		if err != nil {
-			c.JSON(401, gin.H{"error": "Invalid or expired token"})
+			_ = c.Error(apperrors.Wrap(err, apperrors.KindUnauthorized, "Invalid or expired token"))
 			c.Abort()
 			return
 		}
 
+		// Expose the authenticated principal to handlers, the audit log and the request's log lines
+		c.Set(UserIDKey, claims.UserID)
+		c.Set(RoleKey, string(claims.Role))
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), zap.String("user_id", claims.UserID)))
+
 		// Proceed to the next handler if valid
 		c.Next()
 	}
 }
 
-// RoleBasedAccessControl checks the user's role based on a required role
-func RoleBasedAccessControl(requiredRole string) gin.HandlerFunc {
+// RoleBasedAccessControl lets only users with one of the allowed roles through. The role is taken
+// from the token verified by AuthMiddleware, which must run first; headers sent by the client are ignored.
+func RoleBasedAccessControl(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Assume `role` is stored in JWT claims or session
-		userRole := c.GetHeader("X-User-Role")
-
-		if userRole != requiredRole {
-			// Pass an empty slice for errorDetails
-			c.JSON(http.StatusForbidden, utils.StandardErrorResponse("Forbidden: Insufficient permissions", []string{}))
+		if !slices.Contains(allowedRoles, c.GetString(RoleKey)) {
+			_ = c.Error(apperrors.Forbidden("Insufficient permissions"))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"server/pkg/apperrors"
+	"server/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ErrorHandler renders the errors handlers attach with c.Error as RFC 7807 problem+json responses.
+// Register it as the last global middleware: it then also covers route group middlewares such as
+// AuthMiddleware, and writes the response while the Timeout deadline is still in force and before
+// MonitoringMiddleware records the status.
+//
+// When the request's deadline expired or the client disconnected, that takes precedence over the
+// error the handler reported, which is usually just the cancelled query. Errors without an
+// apperrors.Kind are logged and answered with a generic 500.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() {
+			return
+		}
+		err := requestError(c)
+		if err == nil {
+			return
+		}
+
+		problem := apperrors.ProblemFor(err, c.Request.URL.Path)
+		problem.RequestID = c.GetString(RequestIDKey)
+
+		if problem.Status == http.StatusInternalServerError {
+			logger.FromContext(c.Request.Context()).Error("Request failed",
+				zap.String("path", c.FullPath()),
+				zap.Error(err),
+			)
+		}
+
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) && appErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+
+		c.Header("Content-Type", apperrors.ContentType)
+		c.AbortWithStatusJSON(problem.Status, problem)
+	}
+}
+
+// requestError picks the error that describes why the request failed, if any.
+func requestError(c *gin.Context) error {
+	if err := c.Request.Context().Err(); err != nil {
+		return err
+	}
+	if last := c.Errors.Last(); last != nil {
+		return last.Err
+	}
+	return nil
+}
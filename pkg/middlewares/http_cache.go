@@ -0,0 +1,211 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPCache makes successful GET responses cacheable and answers conditional GETs. It buffers the
+// response of the handler, then:
+//   - sets a strong ETag hashed from the body, unless the handler set one, such as a version ETag;
+//   - sets Last-Modified to the latest "updated_at" in a JSON body holding a single resource, unless
+//     the handler set it. Collections rely on the ETag alone: an item leaving a list does not
+//     advance the latest updated_at of those left;
+//   - sets Cache-Control from the max-age of the matched route, falling back to defaultMaxAge.
+//     Routes are keyed like Timeout's, e.g. "GET /api/cities/:id". A max-age of zero lets clients
+//     store the response but makes them revalidate it every time. Responses to requests with an
+//     Authorization header are private;
+//   - answers 304 Not Modified without a body when If-None-Match, or failing that
+//     If-Modified-Since, shows the client already has this response.
+//
+// Register it after MonitoringMiddleware, which then records the status and size actually sent,
+// and before ErrorHandler, whose problem responses pass through untouched.
+func HTTPCache(defaultMaxAge time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffer := &bufferedWriter{ResponseWriter: original, status: original.Status(), size: -1}
+		c.Writer = buffer
+		// A panicking handler skips the rest; Recovery then answers through the real writer
+		defer func() { c.Writer = original }()
+		c.Next()
+		c.Writer = original
+
+		if buffer.status != http.StatusOK || !buffer.Written() {
+			buffer.flush()
+			return
+		}
+
+		header := original.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			etag = contentETag(buffer.body.Bytes())
+			header.Set("ETag", etag)
+		}
+		var lastModified time.Time
+		var hasLastModified bool
+		if value := header.Get("Last-Modified"); value != "" {
+			var err error
+			lastModified, err = http.ParseTime(value)
+			hasLastModified = err == nil
+		} else if lastModified, hasLastModified = latestUpdate(header.Get("Content-Type"), buffer.body.Bytes()); hasLastModified {
+			header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+		if header.Get("Cache-Control") == "" {
+			maxAge, ok := routes[c.Request.Method+" "+c.FullPath()]
+			if !ok {
+				maxAge = defaultMaxAge
+			}
+			header.Set("Cache-Control", cacheControl(maxAge, c.GetHeader("Authorization") != ""))
+		}
+
+		if notModified(c.Request, etag, lastModified, hasLastModified) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+		buffer.flush()
+	}
+}
+
+// bufferedWriter holds back the response of the handlers so HTTPCache can decide what to send.
+// Headers are set on the underlying writer directly.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	size   int // -1 until the header or body is written, like gin's writer
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.body.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int   { return w.status }
+func (w *bufferedWriter) Size() int     { return w.size }
+func (w *bufferedWriter) Written() bool { return w.size != -1 }
+
+// Flush is deferred with the rest of the response.
+func (w *bufferedWriter) Flush() {}
+
+// flush sends the buffered response as the handlers wrote it. The status is forwarded even without
+// a body, such as c.Status(http.StatusNoContent), for gin to send once the handlers return.
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if !w.Written() {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// contentETag derives a strong ETag from the bytes of a response body.
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// latestUpdate finds the latest "updated_at" timestamp in a JSON body holding a single resource, such
+// as a facility and its nested records. Bodies containing arrays are collections and have none.
+func latestUpdate(contentType string, body []byte) (time.Time, bool) {
+	if !strings.HasPrefix(contentType, "application/json") {
+		return time.Time{}, false
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return time.Time{}, false
+	}
+
+	var latest time.Time
+	var walk func(v interface{}) bool
+	walk = func(v interface{}) bool {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if s, ok := value.(string); ok && key == "updated_at" {
+					if t, err := time.Parse(time.RFC3339Nano, s); err == nil && t.After(latest) {
+						latest = t
+					}
+					continue
+				}
+				if !walk(value) {
+					return false
+				}
+			}
+		case []interface{}:
+			return false
+		}
+		return true
+	}
+	if !walk(document) {
+		return time.Time{}, false
+	}
+	return latest, !latest.IsZero()
+}
+
+func cacheControl(maxAge time.Duration, authorized bool) string {
+	scope := "public"
+	if authorized {
+		scope = "private"
+	}
+	if maxAge <= 0 {
+		return scope + ", no-cache"
+	}
+	return scope + ", max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
+
+// notModified evaluates the conditional headers of a GET as RFC 9110 section 13.2.2 orders them:
+// If-None-Match, when present, decides alone.
+func notModified(r *http.Request, etag string, lastModified time.Time, hasLastModified bool) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimSpace(tag)
+			// If-None-Match uses the weak comparison
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || !hasLastModified {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
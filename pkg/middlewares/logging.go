@@ -1,18 +1,53 @@
 package middlewares
 
 import (
+	"time"
+
 	"server/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-func Logging() gin.HandlerFunc {
-	logger.InitLogger("development")
-	defer logger.Sync()
+// Logging writes an access log line for every request once it has been answered: method, route,
+// path, status, latency, response bytes, client IP and, for authenticated requests, the user ID.
+// Register it after RequestID, so the line carries the request ID, and before every middleware
+// that may answer a request itself. The query string is not logged, as it may hold personal data.
+//
+// Successful requests to quietPaths, such as health probes, are logged at debug level only.
+func Logging(quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
 
 	return func(c *gin.Context) {
-		logger.Info("%s %s", zap.Any(c.Request.Method, c.Request.URL.Path))
+		start := time.Now()
+		log := logger.FromContext(c.Request.Context())
+
 		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("bytes", max(c.Writer.Size(), 0)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if user := c.GetString(UserIDKey); user != "" {
+			fields = append(fields, zap.String("user_id", user))
+		}
+
+		switch {
+		case status >= 500:
+			log.Error("Request served", fields...)
+		case status < 400 && quiet[c.Request.URL.Path]:
+			log.Debug("Request served", fields...)
+		default:
+			log.Info("Request served", fields...)
+		}
 	}
 }
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"server/pkg/apperrors"
+	"server/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Recovery turns a panic in a later middleware or handler into a 500 problem response and logs it,
+// with its stack, through the request's logger. Register it right after Logging, in place of
+// gin.Recovery, so the panic is logged with the request ID and the request gets an access log line.
+//
+// http.ErrAbortHandler is re-panicked, as net/http uses it to abort a response silently.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			logger.FromContext(c.Request.Context()).Error("Request panicked",
+				zap.String("path", c.FullPath()),
+				zap.Any("panic", recovered),
+				zap.Stack("stack"),
+			)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			problem := apperrors.ProblemFor(errors.New("panic"), c.Request.URL.Path)
+			problem.RequestID = c.GetString(RequestIDKey)
+			c.Header("Content-Type", apperrors.ContentType)
+			c.AbortWithStatusJSON(problem.Status, problem)
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest is the non-standard status recorded when the client disconnects
+// before a response is written, following the nginx convention.
+const StatusClientClosedRequest = apperrors.StatusClientClosedRequest
+
+// Timeout bounds the request context by the timeout configured for the matched route, falling
+// back to defaultTimeout. Routes are keyed by method and registered path, e.g.
+// "GET /api/admin/audit-logs". A timeout of zero leaves the request without a deadline.
+//
+// Handlers must pass c.Request.Context() down to the repositories so queries are cancelled when
+// the deadline expires or the client disconnects. ErrorHandler, registered after Timeout, answers
+// an expired deadline with 504 Gateway Timeout and a disconnected client with 499.
+func Timeout(defaultTimeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -1,20 +1,29 @@
 package middlewares
 
 import (
+	"server/pkg/sanitize"
+
 	"github.com/gin-gonic/gin"
-	"github.com/microcosm-cc/bluemonday"
 )
 
-func XSSMiddleware() gin.HandlerFunc {
-	policy := bluemonday.UGCPolicy() // Define a policy for safe HTML
+// SanitizeBodiesKey is the gin context key telling handlers to sanitize the bodies they bind.
+const SanitizeBodiesKey = "sanitize_bodies"
 
+// XSSMiddleware strips HTML from request input. Query parameters are sanitized here with the strict
+// policy. JSON bodies are sanitized by the handlers as they bind them, field by field following the
+// `sanitize` tags of the request types (see package sanitize), before they are validated.
+func XSSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Loop through all POST/PUT/QUERY parameters and sanitize them
-		for key, values := range c.Request.Form {
-			for i, value := range values {
-				c.Request.Form[key][i] = policy.Sanitize(value) // Sanitize the input value
+		if c.Request.URL.RawQuery != "" {
+			query := c.Request.URL.Query()
+			for _, values := range query {
+				for i, value := range values {
+					values[i] = sanitize.Strict(value)
+				}
 			}
+			c.Request.URL.RawQuery = query.Encode()
 		}
+		c.Set(SanitizeBodiesKey, true)
 
 		c.Next()
 	}
@@ -0,0 +1,119 @@
+// Package ratelimit implements token bucket rate limits, kept in process or shared through Redis.
+//
+// A bucket holds up to Burst tokens and is refilled at Rate tokens per second. Every request takes
+// a token, and a request that finds the bucket empty is rejected.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit configures a token bucket. The zero Limit is unlimited.
+type Limit struct {
+	// Rate is the number of tokens added per second.
+	Rate float64
+	// Burst is the capacity of the bucket, the number of requests allowed at once.
+	Burst int
+}
+
+// Unlimited reports whether the limit lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Window is how long an empty bucket takes to fill up again.
+func (l Limit) Window() time.Duration {
+	return seconds(float64(l.Burst) / l.Rate)
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long until the next token is added; zero when the request was allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// resultOf describes a bucket of limit left with tokens after a request that was allowed or not.
+func resultOf(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// Store keeps the token buckets, one per key.
+type Store interface {
+	// Take takes a token from the bucket of key, creating a full bucket on first use.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// sweepInterval is how often a MemoryStore drops the buckets that have filled up again, which
+// behave exactly like the buckets it would create in their place.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps token buckets in process, so each instance enforces its limits on its own.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket will be full again
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+// Take takes a token from the bucket of key. It never fails.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for key, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, key)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	result := resultOf(limit, b.tokens, allowed)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
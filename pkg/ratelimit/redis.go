@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// storeErrorsTotal counts Redis failures, each of which fell back to the in-process store.
+var storeErrorsTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "rate_limit_store_errors_total",
+		Help: "Total number of rate limit checks that fell back to the in-process store because Redis failed",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(storeErrorsTotal)
+}
+
+// takeScript refills and takes from a bucket atomically. It reads the clock of the Redis server, so
+// replicas with skewed clocks still agree on how full a bucket is. Buckets expire once they would
+// be full again, which is indistinguishable from a missing bucket.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps token buckets in Redis, so every instance draws from the same buckets. When
+// Redis fails, it falls back to another store, usually a MemoryStore, rather than failing requests.
+type RedisStore struct {
+	client   *redis.Client
+	fallback Store
+}
+
+// NewRedisStore returns a store keeping its buckets in Redis under "ratelimit:<key>".
+func NewRedisStore(client *redis.Client, fallback Store) *RedisStore {
+	return &RedisStore{client: client, fallback: fallback}
+}
+
+// Take takes a token from the bucket of key.
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	result, err := s.take(ctx, key, limit)
+	if err != nil {
+		storeErrorsTotal.Inc()
+		return s.fallback.Take(ctx, key, limit)
+	}
+	return result, nil
+}
+
+func (s *RedisStore) take(ctx context.Context, key string, limit Limit) (Result, error) {
+	reply, err := takeScript.Run(ctx, s.client, []string{"ratelimit:" + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	allowed, _ := reply[0].(int64)
+	remaining, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	return resultOf(limit, tokens, allowed == 1), nil
+}
@@ -0,0 +1,108 @@
+// Package sanitize strips dangerous HTML from decoded request bodies, field by field.
+//
+// Every string reachable from a struct field is sanitized with the policy named by the field's
+// `sanitize` tag:
+//   - strict, the default, removes all HTML and leaves plain text;
+//   - ugc keeps the formatting user-generated content may carry, such as links and emphasis;
+//   - "-" leaves the field untouched, for values such as passwords that must be stored as sent.
+//
+// The tag covers the strings held by the field directly, including those in slices, arrays and
+// maps. Fields of nested structs follow their own tags.
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// TagName is the struct tag that selects a field's policy.
+const TagName = "sanitize"
+
+var (
+	strictPolicy = bluemonday.StrictPolicy()
+	ugcPolicy    = bluemonday.UGCPolicy()
+)
+
+// policies maps tag values to sanitizers; nil skips the field.
+var policies = map[string]func(string) string{
+	"":       Strict,
+	"strict": Strict,
+	"ugc":    UGC,
+	"-":      nil,
+}
+
+// Strict removes all HTML from s. Entities are decoded again when that cannot bring markup back, so
+// plain text such as "Tom & Jerry" is kept as it was sent rather than as "Tom &amp; Jerry".
+func Strict(s string) string {
+	sanitized := strictPolicy.Sanitize(s)
+	if decoded := html.UnescapeString(sanitized); strictPolicy.Sanitize(decoded) == sanitized {
+		return decoded
+	}
+	return sanitized
+}
+
+// UGC removes the HTML that is unsafe in user-generated content and keeps formatting.
+func UGC(s string) string {
+	return ugcPolicy.Sanitize(s)
+}
+
+// Struct sanitizes the strings of v, a pointer to a struct or to a slice of structs, in place.
+// It panics on a tag naming an unknown policy.
+func Struct(v any) {
+	walk(reflect.ValueOf(v), Strict)
+}
+
+// walk sanitizes the strings reachable from v with policy, which nested struct fields replace with
+// their own.
+func walk(v reflect.Value, policy func(string) string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walk(v.Elem(), policy)
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		// Values held by an interface cannot be changed in place
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		walk(elem, policy)
+		v.Set(elem)
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(policy(v.String()))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag := field.Tag.Get(TagName)
+			fieldPolicy, ok := policies[tag]
+			if !ok {
+				panic(fmt.Sprintf("sanitize: unknown policy %q on %s.%s", tag, v.Type(), field.Name))
+			}
+			if fieldPolicy != nil {
+				walk(v.Field(i), fieldPolicy)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), policy)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values are not addressable; sanitize a copy and store it back
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			walk(value, policy)
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+}
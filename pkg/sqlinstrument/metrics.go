@@ -0,0 +1,37 @@
+package sqlinstrument
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Query metrics are labelled with the table and operation Describe finds in the statement, and
+// with the status, success or failure, of the finished query. Statements naming no table, such as
+// SAVEPOINT, have an empty table label.
+var (
+	queryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "database_query_duration_seconds",
+			Help:    "Duration of database queries in seconds, until their rows are closed",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.1, 0.5, 1, 5, 10},
+		},
+		[]string{"table", "operation", "status"},
+	)
+
+	queryTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "database_query_total",
+			Help: "Total number of database queries executed",
+		},
+		[]string{"table", "operation", "status"},
+	)
+
+	inFlightQueries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "database_in_flight_queries",
+			Help: "Number of database queries being executed or having their rows read",
+		},
+		[]string{"table", "operation"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(queryDuration, queryTotal, inFlightQueries)
+}
@@ -0,0 +1,152 @@
+package sqlinstrument
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName names the tracer of query spans.
+const ScopeName = "server/sqlinstrument"
+
+// query is a statement being executed.
+type query struct {
+	span      trace.Span
+	operation string
+	table     string
+	started   time.Time
+}
+
+// start starts the client span of a statement and counts it as in flight. The span is named after
+// the operation and table, such as "SELECT facilities", and carries the sanitized statement.
+func start(ctx context.Context, system, statement string) (context.Context, *query) {
+	operation, table := Describe(statement)
+	inFlightQueries.WithLabelValues(table, operation).Inc()
+	name := operation
+	if table != "" {
+		name += " " + table
+	}
+
+	ctx, span := otel.Tracer(ScopeName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	if span.IsRecording() {
+		span.SetAttributes(
+			semconv.DBSystemKey.String(system),
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+			semconv.DBQueryText(Sanitize(statement)),
+		)
+	}
+	return ctx, &query{span: span, operation: operation, table: table, started: time.Now()}
+}
+
+// end ends the query and records its metrics; only the first call counts.
+func (q *query) end(err error) {
+	if q.span == nil {
+		return
+	}
+	inFlightQueries.WithLabelValues(q.table, q.operation).Dec()
+	// ErrSkip only tells database/sql to run the statement another way, which is measured then
+	if !errors.Is(err, driver.ErrSkip) {
+		status := "success"
+		if err != nil {
+			status = "failure"
+			q.span.RecordError(err)
+			q.span.SetStatus(codes.Error, err.Error())
+		}
+		queryDuration.WithLabelValues(q.table, q.operation, status).Observe(time.Since(q.started).Seconds())
+		queryTotal.WithLabelValues(q.table, q.operation, status).Inc()
+	}
+	q.span.End()
+	q.span = nil
+}
+
+// tableKeywords precede the table a statement works on.
+var tableKeywords = map[string]bool{"FROM": true, "INTO": true, "UPDATE": true, "JOIN": true, "TABLE": true}
+
+// Describe returns the operation of statement, such as SELECT, and the first table it names, or
+// "" when it names none.
+func Describe(statement string) (operation, table string) {
+	words := strings.Fields(statement)
+	if len(words) == 0 {
+		return "", ""
+	}
+	operation = strings.ToUpper(words[0])
+	for i := 0; i < len(words)-1; i++ {
+		if !tableKeywords[strings.ToUpper(words[i])] {
+			continue
+		}
+		next := i + 1
+		if strings.EqualFold(words[next], "ONLY") && next+1 < len(words) {
+			next++
+		}
+		// "INTO t(a, b)" and "FROM "t"," name t; "FROM (SELECT ..." names its table in the subquery
+		name := words[next]
+		if end := strings.IndexFunc(name, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '"'
+		}); end >= 0 {
+			name = name[:end]
+		}
+		name = strings.ReplaceAll(name, `"`, "")
+		if name == "" {
+			continue
+		}
+		if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+			name = name[dot+1:]
+		}
+		return operation, name
+	}
+	return operation, ""
+}
+
+// Sanitize replaces the literals of statement with "?" and collapses its whitespace, so traces
+// never carry values inlined into SQL. Placeholders such as $1 are kept.
+func Sanitize(statement string) string {
+	var b strings.Builder
+	b.Grow(len(statement))
+	runes := []rune(statement)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		case r == '\'':
+			// Skip to the closing quote; '' is an escaped quote
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			r = '?'
+		case unicode.IsDigit(r) && (i == 0 || !isIdentifier(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			r = '?'
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isIdentifier reports whether r may precede a digit within an identifier or a placeholder.
+func isIdentifier(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
@@ -0,0 +1,129 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, sampling and W3C trace-context
+// propagation, the span of every HTTP request, and helpers for the spans of service methods.
+// Queries are traced by package sqlinstrument.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName names the tracer of the server's own spans.
+const ScopeName = "server"
+
+// Exporters
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Options configures Setup.
+type Options struct {
+	ServiceName string
+	Environment string
+	// Exporter is ExporterOTLP, which sends spans to Endpoint over OTLP/HTTP, or ExporterStdout or
+	// ExporterFile, which write them as JSON for local development.
+	Exporter string
+	Endpoint string
+	File     string
+	// SampleRatio is the share of new traces that are recorded. Requests that arrive with a
+	// traceparent follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace-context and baggage propagators.
+// The returned shutdown flushes buffered spans and must be called before the process exits.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	exporter, closeOutput, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.DeploymentEnvironment(opts.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeOutput())
+	}, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+	switch opts.Exporter {
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		return exporter, noClose, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, noClose, err
+	case ExporterFile:
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tracing file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(io.Writer(file)))
+		return exporter, file.Close, err
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+}
+
+// Middleware starts the server span of every request, continuing the trace of the caller's
+// traceparent header, and makes it the parent of the spans started from the request context.
+// Requests to skipPaths, such as health probes, are not traced.
+func Middleware(serviceName string, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		return !skip[c.Request.URL.Path]
+	}))
+}
+
+// Start starts a span named name, such as "FacilityService.UpdateFacility", as a child of the
+// span of ctx. The caller must end it.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(ScopeName).Start(ctx, name, opts...)
+}
+
+// End records err, if any, on span and ends it. Defer it with a named error result:
+//
+//	ctx, span := tracing.Start(ctx, "FacilityService.UpdateFacility")
+//	defer func() { tracing.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -3,27 +3,33 @@ package utils
 import (
 	"fmt"
 	"server/config"
+	"server/pkg/sqlinstrument"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
-// DB initializes and returns a new database connection using sqlx
-func NewDB() (*sqlx.DB, error) {
-	cfg := config.LoadConfig()
-
+// NewDB connects to the configured database and sizes its connection pool.
+func NewDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
 
-	db, err := sqlx.Connect(cfg.Driver, dsn)
+	// Every query is traced, whichever repository or transaction runs it
+	sqlDB, err := sqlinstrument.Open(cfg.Driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to the database: %v", err)
 	}
+	db := sqlx.NewDb(sqlDB, cfg.Driver)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to the database: %v", err)
+	}
 
 	// Set connection pool parameters
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(0)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	return db, nil
 }
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"fmt"
+
+	"server/config"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// NewRedisClient returns a client for the configured Redis server, or nil when no host is set.
+// The client connects lazily, so an unreachable server shows up in readiness checks, not here.
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	if cfg.Host == "" {
+		return nil
+	}
+	return redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
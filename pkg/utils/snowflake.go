@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IDs are laid out as 41 bits of milliseconds since epoch, 5 bits of datacenter ID, 5 bits of
+// machine ID and a 12-bit sequence, leaving the sign bit clear until 2089.
+const (
+	epoch            int64 = 1577836800000 // January 1, 2020, in milliseconds
+	machineIDBits    int64 = 5
+	dataCenterIDBits int64 = 5
+	sequenceBits     int64 = 12
+
+	// MaxMachineID and MaxDataCenterID are the largest node IDs that fit their bit widths.
+	MaxMachineID    int64 = 1<<machineIDBits - 1
+	MaxDataCenterID int64 = 1<<dataCenterIDBits - 1
+
+	sequenceMask int64 = 1<<sequenceBits - 1
+
+	// maxClockRollback is how far the clock may step back before ID generation fails;
+	// shorter rollbacks, such as NTP slews, are waited out.
+	maxClockRollback = 5 * time.Millisecond
+)
+
+var (
+	// ErrInvalidNodeID is returned for machine or datacenter IDs that do not fit their bit widths.
+	ErrInvalidNodeID = errors.New("snowflake node ID out of range")
+	// ErrClockMovedBackwards is returned when the clock is behind the last generated ID by more than
+	// maxClockRollback. Generating IDs then could repeat IDs handed out before the rollback.
+	ErrClockMovedBackwards = errors.New("clock moved backwards")
+)
+
+// SnowflakeNode generates unique, time-ordered IDs for one machine in one datacenter.
+// It is safe for concurrent use; every process needs a distinct (datacenter, machine) pair.
+type SnowflakeNode struct {
+	mu            sync.Mutex
+	dataCenterID  int64
+	machineID     int64
+	sequence      int64
+	lastTimestamp int64
+	now           func() time.Time
+}
+
+// NewSnowflakeNode initializes a SnowflakeNode. now is the clock IDs are stamped with; nil uses time.Now.
+func NewSnowflakeNode(dataCenterID, machineID int64, now func() time.Time) (*SnowflakeNode, error) {
+	if dataCenterID < 0 || dataCenterID > MaxDataCenterID {
+		return nil, fmt.Errorf("%w: datacenter ID %d is not within 0-%d", ErrInvalidNodeID, dataCenterID, MaxDataCenterID)
+	}
+	if machineID < 0 || machineID > MaxMachineID {
+		return nil, fmt.Errorf("%w: machine ID %d is not within 0-%d", ErrInvalidNodeID, machineID, MaxMachineID)
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &SnowflakeNode{dataCenterID: dataCenterID, machineID: machineID, lastTimestamp: -1, now: now}, nil
+}
+
+// NextID generates the next ID of the node.
+func (n *SnowflakeNode) NextID() (int64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	timestamp := n.timestamp()
+
+	if timestamp < n.lastTimestamp {
+		rollback := time.Duration(n.lastTimestamp-timestamp) * time.Millisecond
+		if rollback > maxClockRollback {
+			return 0, fmt.Errorf("%w by %s", ErrClockMovedBackwards, rollback)
+		}
+		for timestamp < n.lastTimestamp {
+			time.Sleep(time.Millisecond)
+			timestamp = n.timestamp()
+		}
+	}
+
+	if timestamp == n.lastTimestamp {
+		n.sequence = (n.sequence + 1) & sequenceMask
+		if n.sequence == 0 {
+			// Sequence exhausted: wait until the next millisecond
+			for timestamp <= n.lastTimestamp {
+				timestamp = n.timestamp()
+			}
+		}
+	} else {
+		n.sequence = 0
+	}
+
+	n.lastTimestamp = timestamp
+
+	// Shift and combine parts
+	id := (timestamp << (machineIDBits + dataCenterIDBits + sequenceBits)) |
+		(n.dataCenterID << (machineIDBits + sequenceBits)) |
+		(n.machineID << sequenceBits) |
+		n.sequence
+
+	return id, nil
+}
+
+// timestamp returns the milliseconds elapsed since the epoch.
+func (n *SnowflakeNode) timestamp() int64 {
+	return n.now().UnixMilli() - epoch
+}
+
+// SnowflakeID is a Snowflake ID split into its parts.
+type SnowflakeID struct {
+	ID           int64     `json:"id,string"`
+	Timestamp    time.Time `json:"timestamp"`
+	DataCenterID int64     `json:"datacenter_id"`
+	MachineID    int64     `json:"machine_id"`
+	Sequence     int64     `json:"sequence"`
+}
+
+// DecodeSnowflakeID extracts the creation time and generating node from id.
+func DecodeSnowflakeID(id int64) SnowflakeID {
+	return SnowflakeID{
+		ID:           id,
+		Timestamp:    time.UnixMilli((id >> (machineIDBits + dataCenterIDBits + sequenceBits)) + epoch).UTC(),
+		DataCenterID: (id >> (machineIDBits + sequenceBits)) & MaxDataCenterID,
+		MachineID:    (id >> sequenceBits) & MaxMachineID,
+		Sequence:     id & sequenceMask,
+	}
+}
+
+// podOrdinal matches the ordinal suffix of a StatefulSet pod name such as "api-3".
+var podOrdinal = regexp.MustCompile(`-(\d+)$`)
+
+// MachineIDFromHostname derives a machine ID from the ordinal of a StatefulSet pod's hostname,
+// so each replica gets a distinct ID without per-pod configuration.
+func MachineIDFromHostname(hostname string) (int64, error) {
+	match := podOrdinal.FindStringSubmatch(hostname)
+	if match == nil {
+		return 0, fmt.Errorf("hostname %q has no pod ordinal", hostname)
+	}
+	ordinal, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || ordinal > MaxMachineID {
+		return 0, fmt.Errorf("%w: pod ordinal %s is not within 0-%d", ErrInvalidNodeID, match[1], MaxMachineID)
+	}
+	return ordinal, nil
+}
+
+var (
+	defaultNode   *SnowflakeNode
+	defaultNodeMu sync.RWMutex
+)
+
+// SetDefaultSnowflakeNode sets the node used by GenerateSnowflakeID.
+func SetDefaultSnowflakeNode(node *SnowflakeNode) {
+	defaultNodeMu.Lock()
+	defer defaultNodeMu.Unlock()
+	defaultNode = node
+}
+
+// GenerateSnowflakeID generates an ID on the node set by SetDefaultSnowflakeNode.
+func GenerateSnowflakeID() (int64, error) {
+	defaultNodeMu.RLock()
+	node := defaultNode
+	defaultNodeMu.RUnlock()
+
+	if node == nil {
+		return 0, errors.New("no snowflake node configured")
+	}
+	return node.NextID()
+}
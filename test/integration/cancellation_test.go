@@ -0,0 +1,52 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelledContextAbortsRunningQuery(t *testing.T) {
+	db := connectFreshDB(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := db.ExecContext(ctx, `SELECT pg_sleep(5)`)
+
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// The connection must be usable again once the cancelled query has been aborted
+	var one int
+	require.NoError(t, db.GetContext(context.Background(), &one, `SELECT 1`))
+}
+
+func TestTransactorRollsBackWhenContextIsCancelled(t *testing.T) {
+	db := connectFreshDB(t)
+	db.SetMaxOpenConns(1) // Keep the temp table visible to every query
+	_, err := db.Exec(`CREATE TEMP TABLE cancel_probe (id INT)`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = repositories.NewTransactor(db).WithinAudit(ctx, repositories.Actor{}, func(tx repositories.DBTX) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO cancel_probe VALUES (1)`); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `SELECT pg_sleep(5)`)
+		return err
+	})
+	require.Error(t, err)
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM cancel_probe`))
+	assert.Zero(t, count)
+}
@@ -0,0 +1,74 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server/db/migrations"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/migrate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFacilityMetricsArePartitionedByDay(t *testing.T) {
+	db := connectFreshDB(t)
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(ctx))
+
+	facilityID := time.Now().UnixNano()
+	_, err = db.Exec(`INSERT INTO facilities (id, name, type, location) VALUES ($1, 'Metrics Probe', 'Clinic', 'Basra')`, facilityID)
+	require.NoError(t, err)
+
+	repo := repositories.NewFacilityMetricsRepository(db)
+	past := time.Now().UTC().AddDate(0, 0, -30).Truncate(24 * time.Hour)
+	require.NoError(t, repo.EnsurePartitions(ctx, past, past))
+
+	stored, err := repo.Insert(ctx, []models.FacilityMetrics{
+		{FacilityID: facilityID, MetricType: models.ERWaitMinutes, MetricValue: 20, MeasuredAt: past.Add(10 * time.Minute)},
+		{FacilityID: facilityID, MetricType: models.ERWaitMinutes, MetricValue: 40, MeasuredAt: past.Add(20 * time.Minute)},
+		{FacilityID: facilityID, MetricType: models.ERWaitMinutes, MetricValue: 90, MeasuredAt: past.Add(70 * time.Minute)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stored)
+
+	// Sending a sample again overwrites it
+	_, err = repo.Insert(ctx, []models.FacilityMetrics{
+		{FacilityID: facilityID, MetricType: models.ERWaitMinutes, MetricValue: 30, MeasuredAt: past.Add(20 * time.Minute)},
+	})
+	require.NoError(t, err)
+
+	buckets, err := repo.Buckets(ctx, repositories.FacilityMetricsQuery{
+		FacilityID: facilityID, From: past, To: past.Add(2 * time.Hour), Bucket: time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, buckets, 2)
+	assert.True(t, buckets[0].Start.Equal(past))
+	assert.Equal(t, 25.0, buckets[0].Avg)
+	assert.Equal(t, 20.0, buckets[0].Min)
+	assert.Equal(t, 30.0, buckets[0].Max)
+	assert.Equal(t, int64(2), buckets[0].Count)
+	assert.Equal(t, int64(1), buckets[1].Count)
+
+	// Samples of days without a partition cannot be stored
+	_, err = repo.Insert(ctx, []models.FacilityMetrics{
+		{FacilityID: facilityID, MetricType: models.DailyPatients, MetricValue: 1, MeasuredAt: past.AddDate(0, 0, -1)},
+	})
+	assert.Error(t, err)
+
+	dropped, err := repo.DropPartitionsBefore(ctx, past.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Contains(t, dropped, "facility_metrics_p"+past.Format("20060102"))
+
+	buckets, err = repo.Buckets(ctx, repositories.FacilityMetricsQuery{
+		FacilityID: facilityID, From: past, To: past.Add(2 * time.Hour), Bucket: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, buckets)
+}
@@ -0,0 +1,78 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"server/db/migrations"
+	"server/pkg/migrate"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectFreshDB connects to the database named by TEST_DATABASE_URL, which must be empty.
+// These tests are meant for CI, where a disposable PostgreSQL instance is provided.
+func connectFreshDB(t *testing.T) *sqlx.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrationsApplyAndRollBackOnFreshDatabase(t *testing.T) {
+	db := connectFreshDB(t)
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+
+	// Apply everything
+	require.NoError(t, migrator.Up(ctx))
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), version)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "migration %d_%s should be applied", status.Version, status.Name)
+	}
+
+	// Every down migration must cleanly undo its up migration
+	require.NoError(t, migrator.To(ctx, 0))
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+
+	// And the schema must be re-creatable afterwards
+	require.NoError(t, migrator.Up(ctx))
+}
+
+func TestConcurrentMigrationRunsApplyOnce(t *testing.T) {
+	db := connectFreshDB(t)
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { errs <- migrator.Up(ctx) }()
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	var applied int
+	require.NoError(t, db.Get(&applied, `SELECT COUNT(*) FROM schema_migrations`))
+	assert.Equal(t, len(migrator.Migrations()), applied)
+}
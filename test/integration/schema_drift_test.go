@@ -0,0 +1,31 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"server/db/migrations"
+	"server/internal/models"
+	"server/internal/schemacheck"
+	"server/pkg/migrate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestModelsMatchMigratedSchema fails the build when a model drifts from the schema.
+func TestModelsMatchMigratedSchema(t *testing.T) {
+	db := connectFreshDB(t)
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(ctx))
+
+	drifts, err := schemacheck.Check(ctx, db, models.AllTables()...)
+	require.NoError(t, err)
+	for _, drift := range drifts {
+		t.Error(drift)
+	}
+	assert.Empty(t, drifts)
+}
@@ -0,0 +1,76 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server/db/migrations"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/pkg/migrate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoredFacilityVersionAdvances(t *testing.T) {
+	db := connectFreshDB(t)
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(ctx))
+
+	facilityID := time.Now().UnixNano()
+	_, err = db.Exec(`INSERT INTO facilities (id, name, type, location) VALUES ($1, 'Restore Probe', 'Clinic', 'Basra')`, facilityID)
+	require.NoError(t, err)
+
+	var snapshot models.JSONMap
+	require.NoError(t, db.Get(&snapshot, `SELECT to_jsonb(f) FROM facilities f WHERE id = $1`, facilityID))
+
+	var before time.Time
+	require.NoError(t, db.Get(&before, `UPDATE facilities SET name = 'Renamed Probe', updated_at = clock_timestamp() WHERE id = $1 RETURNING updated_at`, facilityID))
+
+	transactor := repositories.NewTransactor(db)
+	err = transactor.WithinTransaction(ctx, repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		return uow.Snapshots().Upsert(ctx, "facilities", snapshot)
+	})
+	require.NoError(t, err)
+
+	var restored struct {
+		Name      string    `db:"name"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	require.NoError(t, db.Get(&restored, `SELECT name, updated_at FROM facilities WHERE id = $1`, facilityID))
+	assert.Equal(t, "Restore Probe", restored.Name)
+	assert.True(t, restored.UpdatedAt.After(before), "restored version %s is not newer than %s", restored.UpdatedAt, before)
+}
+
+func TestRestoringTrashedFacilityTakesItOutOfTrash(t *testing.T) {
+	db := connectFreshDB(t)
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(ctx))
+
+	facilityID := time.Now().UnixNano()
+	_, err = db.Exec(`INSERT INTO facilities (id, name, type, location) VALUES ($1, 'Trash Probe', 'Clinic', 'Basra')`, facilityID)
+	require.NoError(t, err)
+
+	var asOf time.Time
+	require.NoError(t, db.Get(&asOf, `SELECT clock_timestamp()`))
+	_, err = db.Exec(`UPDATE facilities SET deleted_at = clock_timestamp() WHERE id = $1`, facilityID)
+	require.NoError(t, err)
+
+	transactor := repositories.NewTransactor(db)
+	service := services.NewAuditService(repositories.NewAuditLogRepository(db), transactor)
+	_, err = service.RestoreFacility(ctx, repositories.Actor{}, facilityID, asOf)
+	require.NoError(t, err)
+
+	var deletedAt *time.Time
+	require.NoError(t, db.Get(&deletedAt, `SELECT deleted_at FROM facilities WHERE id = $1`, facilityID))
+	assert.Nil(t, deletedAt)
+}
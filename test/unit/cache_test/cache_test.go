@@ -0,0 +1,183 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"server/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var options = cache.Options{LocalSize: 100, LocalTTL: time.Minute, TTL: time.Minute}
+
+type city struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+// loader counts its calls and returns the city it is given.
+func loader(calls *atomic.Int32, value city) func(context.Context) (city, error) {
+	return func(context.Context) (city, error) {
+		calls.Add(1)
+		return value, nil
+	}
+}
+
+func TestGetFillsBothTiers(t *testing.T) {
+	server, client := newRedis(t)
+	ctx := context.Background()
+	var calls atomic.Int32
+
+	first := cache.New[city]("tiers", client, options)
+	value, err := first.Get(ctx, "1", loader(&calls, city{1, "Najaf"}))
+	require.NoError(t, err)
+	assert.Equal(t, city{1, "Najaf"}, value)
+	assert.True(t, server.Exists("cache:tiers:1"))
+
+	// Another instance finds the value in Redis; the first one in its local tier
+	second := cache.New[city]("tiers", client, options)
+	value, err = second.Get(ctx, "1", loader(&calls, city{1, "stale"}))
+	require.NoError(t, err)
+	assert.Equal(t, city{1, "Najaf"}, value)
+
+	server.FlushAll()
+	value, err = first.Get(ctx, "1", loader(&calls, city{1, "stale"}))
+	require.NoError(t, err)
+	assert.Equal(t, city{1, "Najaf"}, value)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetLoadsConcurrentMissesOnce(t *testing.T) {
+	c := cache.New[city]("stampede", nil, options)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (city, error) {
+		calls.Add(1)
+		<-release
+		return city{2, "Basra"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.Get(context.Background(), "2", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "Basra", value.Name)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetSharedLoadSurvivesTheCallerThatStartedIt(t *testing.T) {
+	c := cache.New[city]("detached", nil, options)
+	started, release := make(chan struct{}), make(chan struct{})
+	load := func(ctx context.Context) (city, error) {
+		close(started)
+		select {
+		case <-release:
+			return city{3, "Erbil"}, nil
+		case <-ctx.Done():
+			return city{}, ctx.Err()
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := c.Get(firstCtx, "3", load)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan city, 1)
+	go func() {
+		value, err := c.Get(context.Background(), "3", load)
+		assert.NoError(t, err)
+		second <- value
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first caller gives up without cancelling the load the second one waits for
+	cancelFirst()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	assert.Equal(t, "Erbil", (<-second).Name)
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	c := cache.New[city]("errors", nil, options)
+	errNotFound := errors.New("not found")
+
+	_, err := c.Get(context.Background(), "3", func(context.Context) (city, error) { return city{}, errNotFound })
+	assert.ErrorIs(t, err, errNotFound)
+
+	value, err := c.Get(context.Background(), "3", func(context.Context) (city, error) { return city{3, "Erbil"}, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "Erbil", value.Name)
+}
+
+func TestInvalidateIsBroadcastToOtherInstances(t *testing.T) {
+	server, client := newRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	writer := cache.New[city]("broadcast", client, options)
+	reader := cache.New[city]("broadcast", client, options)
+	go reader.Run(ctx)
+	require.Eventually(t, func() bool {
+		return server.PubSubNumSub("cache:invalidate:broadcast")["cache:invalidate:broadcast"] == 1
+	}, time.Second, 5*time.Millisecond)
+
+	var calls atomic.Int32
+	_, err := reader.Get(ctx, "4", loader(&calls, city{4, "Mosul"}))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Invalidate(ctx, "4"))
+	assert.False(t, server.Exists("cache:broadcast:4"))
+
+	assert.Eventually(t, func() bool {
+		value, err := reader.Get(ctx, "4", loader(&calls, city{4, "Mosul (renamed)"}))
+		return err == nil && value.Name == "Mosul (renamed)"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestInvalidateAllDropsEveryEntry(t *testing.T) {
+	server, client := newRedis(t)
+	ctx := context.Background()
+	c := cache.New[city]("all", client, options)
+	var calls atomic.Int32
+
+	for _, key := range []string{"5", "6"} {
+		_, err := c.Get(ctx, key, loader(&calls, city{Name: "Kirkuk"}))
+		require.NoError(t, err)
+	}
+	require.NoError(t, server.Set("unrelated", "kept"))
+
+	require.NoError(t, c.InvalidateAll(ctx))
+
+	assert.Equal(t, []string{"unrelated"}, server.Keys())
+	_, err := c.Get(ctx, "5", loader(&calls, city{Name: "Kirkuk"}))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
@@ -0,0 +1,136 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"server/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validSecret = "0123456789abcdef0123456789abcdef"
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadLayersSources(t *testing.T) {
+	file := writeFile(t, `
+server:
+  port: "9000"
+  request_timeout: 3s
+database:
+  name: from_file
+  max_open_conns: 50
+`)
+	t.Setenv(config.FileEnv, file)
+	t.Setenv("DB_NAME", "from_env")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://mydoctor.iq, https://admin.mydoctor.iq")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /api/login=2/10s, GET /api/facilities/search=0")
+
+	cfg, err := config.Load([]string{"-database.name=from_flag", "-server.route_timeouts=GET /api/facilities=2s"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "9000", cfg.Server.Port)
+	assert.Equal(t, 3*time.Second, cfg.Server.RequestTimeout)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, "from_flag", cfg.Database.Name)
+	assert.Equal(t, []string{"https://mydoctor.iq", "https://admin.mydoctor.iq"}, cfg.CORS.AllowedOrigins)
+	assert.Equal(t, map[string]time.Duration{"GET /api/facilities": 2 * time.Second}, cfg.Server.RouteTimeouts)
+	assert.Equal(t, map[string]config.Rate{
+		"POST /api/login":            {Requests: 2, Period: 10 * time.Second},
+		"GET /api/facilities/search": {},
+	}, cfg.RateLimit.Routes)
+	assert.Equal(t, "localhost", cfg.Database.Host, "defaults fill unset settings")
+}
+
+func TestLoadReportsEveryMalformedValue(t *testing.T) {
+	t.Setenv("DB_PORT", "fifty")
+	t.Setenv("FEATURE_METRICS", "sometimes")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /api/login=5 per minute")
+
+	_, err := config.Load([]string{"-server.shutdown_timeout=soon"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `DB_PORT: invalid integer "fifty"`)
+	assert.Contains(t, err.Error(), `FEATURE_METRICS: invalid boolean "sometimes"`)
+	assert.Contains(t, err.Error(), `-server.shutdown_timeout: invalid duration "soon"`)
+	assert.Contains(t, err.Error(), `RATE_LIMIT_ROUTES: invalid rate "5 per minute"`)
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	_, err := config.Load([]string{"-config", writeFile(t, "server:\n  prot: 9000\n")})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field prot not found")
+}
+
+func TestValidateAggregatesErrors(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.GinMode = "verbose"
+	cfg.Database.MaxIdleConns = 100
+	cfg.CORS.AllowedOrigins = []string{"*", "https://mydoctor.iq"}
+	cfg.Notifications.SMS.Enabled = true
+
+	err := cfg.Validate()
+
+	require.Error(t, err)
+	for _, path := range []string{
+		"server.gin_mode", "database.max_idle_conns", "auth.jwt_secret", "cors.allowed_origins",
+		"notifications.sms.provider", "notifications.sms.api_key",
+	} {
+		assert.Contains(t, err.Error(), path+":")
+	}
+
+	cfg = config.Default()
+	cfg.Auth.JWTSecret = validSecret
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateRejectsAnyOriginWithCredentialsOrInProduction(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = validSecret
+	cfg.CORS.AllowCredentials = true
+	assert.ErrorContains(t, cfg.Validate(), `cors.allowed_origins: "*" cannot be combined with allow_credentials`)
+
+	cfg.CORS.AllowCredentials = false
+	cfg.Server.Env = "production"
+	assert.ErrorContains(t, cfg.Validate(), `cors.allowed_origins: "*" is not allowed in production`)
+
+	cfg.CORS.AllowedOrigins = []string{"https://mydoctor.iq"}
+	cfg.CORS.AllowCredentials = true
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateRequiresRateLimitingInProduction(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = validSecret
+	cfg.CORS.AllowedOrigins = []string{"https://mydoctor.iq"}
+	cfg.Server.Env = "production"
+	require.True(t, cfg.RateLimit.Enabled, "rate limiting is on by default")
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RateLimit.Enabled = false
+	assert.ErrorContains(t, cfg.Validate(), "rate_limit.enabled: cannot be disabled in production")
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = validSecret
+	cfg.Database.Password = "hunter2"
+
+	var out strings.Builder
+	require.NoError(t, cfg.Print(&out))
+
+	assert.NotContains(t, out.String(), validSecret)
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.Contains(t, out.String(), "jwt_secret: '[REDACTED]' # env JWT_SECRET")
+	assert.Contains(t, out.String(), "request_timeout: 10s # env REQUEST_TIMEOUT")
+}
@@ -0,0 +1,68 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"server/internal/handlers"
+	"server/pkg/logger"
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func postCSPReport(contentType, body string, guards ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	handlers.RegisterCSPReportRoutes(router, guards...)
+
+	req := httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCSPReportAcceptsBothReportFormats(t *testing.T) {
+	legacy := `{"csp-report":{"document-uri":"https://mydoctor.iq/","violated-directive":"script-src","blocked-uri":"https://evil.example/x.js"}}`
+	assert.Equal(t, http.StatusNoContent, postCSPReport("application/csp-report", legacy).Code)
+
+	reporting := `[{"type":"csp-violation","body":{"documentURL":"https://mydoctor.iq/","effectiveDirective":"img-src","blockedURL":"data"}}]`
+	assert.Equal(t, http.StatusNoContent, postCSPReport("application/reports+json", reporting).Code)
+}
+
+func TestCSPReportRejectsMalformedReports(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, postCSPReport("application/csp-report", "not json").Code)
+	assert.Equal(t, http.StatusBadRequest, postCSPReport("application/csp-report", strings.Repeat("a", 70<<10)).Code)
+}
+
+func TestCSPReportLogsOneLinePerReport(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger.SetLogger(zap.New(core))
+	t.Cleanup(func() { logger.SetLogger(zap.NewNop()) })
+
+	batch := `[{"type":"csp-violation","body":{"effectiveDirective":"img-src","blockedURL":"data"}},
+		{"type":"csp-violation","body":{"effectiveDirective":"script-src","blockedURL":"inline"}},
+		{"type":"csp-violation","body":{"effectiveDirective":"style-src","blockedURL":"inline"}}]`
+	require.Equal(t, http.StatusNoContent, postCSPReport("application/reports+json", batch).Code)
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, int64(3), fields["violations"])
+	assert.Equal(t, "img-src", fields["directive"])
+}
+
+func TestCSPReportRunsGuardsFirst(t *testing.T) {
+	limited := func(c *gin.Context) { c.AbortWithStatus(http.StatusTooManyRequests) }
+
+	legacy := `{"csp-report":{"violated-directive":"script-src"}}`
+	assert.Equal(t, http.StatusTooManyRequests, postCSPReport("application/csp-report", legacy, limited).Code)
+}
@@ -0,0 +1,406 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"server/internal/handlers"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/pkg/middlewares"
+	"server/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const facilityBody = `{"name":"Al-Kindi Hospital","description":"Teaching hospital","type":"Teaching Hospital","city_id":"1"}`
+
+func newFacilityRouter(t *testing.T, middleware ...gin.HandlerFunc) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "postgres")
+	service := services.NewFacilityService(repositories.NewFacilityRepository(db), repositories.NewTransactor(db))
+
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.Use(middleware...)
+	handler := handlers.NewFacilityHandler(service)
+	handler.RegisterFacilityRoutes(router.Group("/api"))
+	handler.RegisterFacilityWriteRoutes(router.Group("/api"))
+	handler.RegisterFacilityStaffRoutes(router.Group("/api"))
+	handler.RegisterFacilityAdminRoutes(router.Group("/api/admin"))
+	return router, mock
+}
+
+func facilityRow(updatedAt time.Time) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "updated_at"}).AddRow(7, "Al-Kindi Hospital", updatedAt)
+}
+
+func serve(router *gin.Engine, method, path, ifMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(facilityBody))
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFacilityUpdateRoundTripsETag(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+	readAt := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	writtenAt := readAt.Add(time.Second)
+
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE id = \\$1").WillReturnRows(facilityRow(readAt))
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("UPDATE facilities SET (.+) WHERE id = \\$5 AND updated_at = \\$6").
+		WithArgs(1, "Teaching hospital", "Al-Kindi Hospital", "Teaching Hospital", int64(7), readAt.Local()).
+		WillReturnRows(facilityRow(writtenAt))
+	mock.ExpectCommit()
+
+	get := serve(router, http.MethodGet, "/api/facilities/7", "")
+	require.Equal(t, http.StatusOK, get.Code)
+	etag := get.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	put := serve(router, http.MethodPut, "/api/facilities/7", etag)
+
+	assert.Equal(t, http.StatusOK, put.Code)
+	assert.NotEqual(t, etag, put.Header().Get("ETag"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacilityIDsAreSerializedAsStrings(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+
+	// Snowflake IDs exceed 2^53, the largest integer JavaScript numbers hold exactly
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE id = \\$1").
+		WithArgs(int64(1234567890123456789)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "city_id", "category_id"}).
+			AddRow(int64(1234567890123456789), "Al-Kindi Hospital", int64(1234567890123456790), nil))
+
+	w := serve(router, http.MethodGet, "/api/facilities/1234567890123456789", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"1234567890123456789"`)
+	assert.Contains(t, w.Body.String(), `"city_id":"1234567890123456790"`)
+	assert.Contains(t, w.Body.String(), `"category_id":null`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFacilityReportsOnlyMissingRowsAsNotFound(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE id = \\$1").WithArgs(int64(7)).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE id = \\$1").WithArgs(int64(8)).WillReturnError(sql.ErrConnDone)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/api/facilities/7", "").Code)
+	assert.Equal(t, http.StatusInternalServerError, serve(router, http.MethodGet, "/api/facilities/8", "").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacilityUpdateWithStaleETagIsRejected(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("UPDATE facilities").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	w := serve(router, http.MethodPut, "/api/facilities/7", `"5z1k9q3g0w"`)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// auditedRequestID matches the request ID stamped on an audited transaction, which must fit
+// audit_log.request_id.
+type auditedRequestID struct{ rejected string }
+
+func (a auditedRequestID) Match(v driver.Value) bool {
+	id, ok := v.(string)
+	return ok && id != "" && len(id) <= 64 && id != a.rejected
+}
+
+func TestLongRequestIDsDoNotBreakAuditedWrites(t *testing.T) {
+	router, mock := newFacilityRouter(t, middlewares.RequestID())
+	longID := strings.Repeat("r", 100)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").
+		WithArgs("", auditedRequestID{rejected: longID}, "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("UPDATE facilities").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/facilities/7", strings.NewReader(facilityBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"5z1k9q3g0w"`)
+	req.Header.Set(middlewares.RequestIDHeader, longID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.NotEqual(t, longID, w.Header().Get(middlewares.RequestIDHeader))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacilityUpdateRequiresIfMatch(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+
+	assert.Equal(t, http.StatusPreconditionRequired, serve(router, http.MethodPut, "/api/facilities/7", "").Code)
+	assert.Equal(t, http.StatusPreconditionFailed, serve(router, http.MethodPatch, "/api/facilities/7", `W/"5z1k9q3g0w"`).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacilityWriteRoutesRequireAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	handlers.RegisterHandlers(router, &handlers.Services{
+		AuthService: services.NewAuthService(nil, []byte("0123456789abcdef0123456789abcdef"), time.Hour),
+	})
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/facilities"},
+		{http.MethodPut, "/api/facilities/7"},
+		{http.MethodPatch, "/api/facilities/7"},
+		{http.MethodDelete, "/api/facilities/7"},
+		{http.MethodPost, "/api/facilities/7/doctors"},
+		{http.MethodPut, "/api/facilities/7/services"},
+		{http.MethodDelete, "/api/facilities/7/doctors/3"},
+		{http.MethodPost, "/api/facilities/7/appointments"},
+		{http.MethodDelete, "/api/facilities/7/appointments/11"},
+		{http.MethodPost, "/api/facilities/7/review"},
+	} {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			assert.Equal(t, http.StatusUnauthorized, serve(router, route.method, route.path, `"5z1k9q3g0w"`).Code)
+		})
+	}
+}
+
+func TestFacilityManagementRoutesRequireStaff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService(nil, []byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	handlers.RegisterHandlers(router, &handlers.Services{AuthService: authService})
+
+	token, err := authService.GenerateAuthToken(&models.User{BaseModel: models.BaseModel{ID: 42}, Role: models.RoleUser})
+	require.NoError(t, err)
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/facilities"},
+		{http.MethodPut, "/api/facilities/7"},
+		{http.MethodPatch, "/api/facilities/7"},
+		{http.MethodDelete, "/api/facilities/7"},
+		{http.MethodPost, "/api/facilities/7/doctors"},
+		{http.MethodPut, "/api/facilities/7/services"},
+		{http.MethodDelete, "/api/facilities/7/doctors/3"},
+		{http.MethodPost, "/api/facilities/7/appointments/11/no-show"},
+	} {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			req := httptest.NewRequest(route.method, route.path, strings.NewReader(facilityBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		})
+	}
+}
+
+func TestAdminFacilityListIncludesTrashedOnRequest(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM facilities WHERE deleted_at IS NULL ORDER BY id")).WillReturnRows(facilityRow(time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM facilities ORDER BY id")).WillReturnRows(facilityRow(time.Now()))
+
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/api/admin/facilities", "").Code)
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/api/admin/facilities?include_deleted=true", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, http.MethodGet, "/api/admin/facilities?include_deleted=maybe", "").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateFacilityRejectsInvalidBodyInClientLanguage(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+
+	body := `{"name":"Al-Kindi Hospital","description":"Teaching hospital","type":"Spa","city_id":"1","location":"Baghdad"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/facilities", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "ar")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"type"`)
+	assert.Contains(t, w.Body.String(), "بيانات الطلب غير صالحة")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateFacilitySanitizesBodyBeforeValidation(t *testing.T) {
+	router, mock := newFacilityRouter(t, middlewares.XSSMiddleware())
+
+	// The name is nothing but markup, so it is empty once sanitized and fails "required"
+	body := `{"name":"<script>alert(1)</script>","description":"<b>Teaching</b> hospital","type":"Teaching Hospital","city_id":"1","location":"Baghdad"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/facilities", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"name"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func post(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// counterValue reads a counter of the default registry by its labels.
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if labels[pair.GetName()] != pair.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestBookAppointmentCountsBookingsByFacilityTypeAndCity(t *testing.T) {
+	node, err := utils.NewSnowflakeNode(0, 1, nil)
+	require.NoError(t, err)
+	utils.SetDefaultSnowflakeNode(node)
+	router, mock := newFacilityRouter(t)
+	created := map[string]string{"event": "created", "facility_type": "Clinic", "city": "Basra"}
+	before := counterValue(t, "bookings_total", created)
+	notFound := counterValue(t, "booking_failures_total", map[string]string{"reason": "not_found"})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type", "city_id"}).AddRow(7, "Al-Kindi Clinic", "Clinic", 3))
+	mock.ExpectQuery("SELECT (.+) FROM cities WHERE").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Basra"))
+	mock.ExpectQuery("INSERT INTO facility_appointments").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "facility_id", "status"}).AddRow(11, 7, "Scheduled"))
+	mock.ExpectCommit()
+
+	appointmentTime := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	w := post(router, "/api/facilities/7/appointments", `{"patient_name":"Zainab","appointment_time":"`+appointmentTime+`"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, before+1, counterValue(t, "bookings_total", created))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	w = post(router, "/api/facilities/8/appointments", `{"patient_name":"Zainab","appointment_time":"`+appointmentTime+`"}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, notFound+1, counterValue(t, "booking_failures_total", map[string]string{"reason": "not_found"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchFacilitiesCountsSearchesFindingNothing(t *testing.T) {
+	router, mock := newFacilityRouter(t)
+	searches := counterValue(t, "facility_searches_total", nil)
+	zeroResults := counterValue(t, "facility_search_zero_results_total", nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM facilities WHERE \(name ILIKE \$1 OR location ILIKE \$1\) AND type = \$2`).
+		WithArgs(`%100\%%`, "Clinic", 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/facilities/search?q=100%25&type=Clinic", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"facilities":[]}`, w.Body.String())
+	assert.Equal(t, searches+1, counterValue(t, "facility_searches_total", nil))
+	assert.Equal(t, zeroResults+1, counterValue(t, "facility_search_zero_results_total", nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// signedIn stands in for AuthMiddleware, authenticating every request as the user with the role.
+func signedIn(userID string, role models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middlewares.UserIDKey, userID)
+		c.Set(middlewares.RoleKey, string(role))
+	}
+}
+
+func TestCancelAppointmentRequiresOwnerOrStaff(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		role   models.UserRole
+		want   int
+	}{
+		{"user who booked", "42", models.RoleUser, http.StatusOK},
+		{"another user", "43", models.RoleUser, http.StatusForbidden},
+		{"staff", "7", models.RoleStaff, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mock := newFacilityRouter(t, signedIn(tt.userID, tt.role))
+
+			mock.ExpectBegin()
+			mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT (.+) FROM facility_appointments WHERE").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "facility_id", "status", "appointment_time", "booked_by"}).
+					AddRow(11, 7, "Scheduled", time.Now().Add(time.Hour), 42))
+			if tt.want == http.StatusOK {
+				mock.ExpectQuery("SELECT (.+) FROM facilities WHERE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type"}).AddRow(7, "Al-Kindi Clinic", "Clinic"))
+				mock.ExpectQuery("UPDATE facility_appointments").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "facility_id", "status"}).AddRow(11, 7, "Cancelled"))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			w := serve(router, http.MethodDelete, "/api/facilities/7/appointments/11", "")
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"server/internal/handlers"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/pkg/middlewares"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFacilityMetricsService(t *testing.T) (*services.FacilityMetricsService, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "postgres")
+	return services.NewFacilityMetricsService(repositories.NewFacilityMetricsRepository(db),
+		repositories.NewFacilityRepository(db), 7*24*time.Hour), mock
+}
+
+func newFacilityMetricsRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+	service, mock := newFacilityMetricsService(t)
+
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	handler := handlers.NewFacilityMetricsHandler(service)
+	handler.RegisterFacilityMetricsRoutes(router.Group("/api"))
+	handler.RegisterFacilityMetricsStaffRoutes(router.Group("/api"))
+	return router, mock
+}
+
+func TestIngestFacilityMetricsStoresBatchWithoutDuplicates(t *testing.T) {
+	router, mock := newFacilityMetricsRouter(t)
+	measuredAt := time.Now().UTC().Truncate(time.Minute)
+	day := measuredAt.Format(time.DateOnly)
+
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT create_facility_metrics_partition(day::date)")).
+		WithArgs(day, day).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO facility_metrics").
+		WithArgs("{7,7}", `{"er_wait_minutes","bed_occupancy"}`, "{40,91}", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	at := measuredAt.Format(time.RFC3339)
+	w := post(router, "/api/facilities/7/metrics", `{"metrics":[
+		{"metric_type":"er_wait_minutes","metric_value":35,"measured_at":"`+at+`"},
+		{"metric_type":"bed_occupancy","metric_value":91,"measured_at":"`+at+`"},
+		{"metric_type":"er_wait_minutes","metric_value":40,"measured_at":"`+at+`"}]}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"facility_id":"7","stored":2}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIngestFacilityMetricsRejectsInvalidSamples(t *testing.T) {
+	router, mock := newFacilityMetricsRouter(t)
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	old := time.Now().Add(-8 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	w := post(router, "/api/facilities/7/metrics", `{"metrics":[
+		{"metric_type":"daily_patients","metric_value":120,"measured_at":"`+old+`"}]}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"metrics[0].measured_at"`)
+
+	w = post(router, "/api/facilities/7/metrics", `{"metrics":[{"metric_type":"heart_rate","metric_value":1,"measured_at":"`+old+`"}]}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "bed_occupancy, er_wait_minutes, daily_patients")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryFacilityMetricsRejectsTooManyBuckets(t *testing.T) {
+	router, mock := newFacilityMetricsRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/facilities/7/metrics?from=2024-05-01T00:00:00Z&to=2024-05-02T00:00:00Z&bucket=1m", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "more than 1000 buckets")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStaffCanIngestFacilityMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service, mock := newFacilityMetricsService(t)
+	authService := services.NewAuthService(nil, []byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	handlers.RegisterHandlers(router, &handlers.Services{AuthService: authService, FacilityMetricsService: service})
+
+	tokenFor := func(role models.UserRole) string {
+		token, err := authService.GenerateAuthToken(&models.User{BaseModel: models.BaseModel{ID: 42}, Role: role})
+		require.NoError(t, err)
+		return token
+	}
+	ingest := func(token string) *httptest.ResponseRecorder {
+		at := time.Now().UTC().Truncate(time.Minute).Format(time.RFC3339)
+		req := httptest.NewRequest(http.MethodPost, "/api/facilities/7/metrics",
+			strings.NewReader(`{"metrics":[{"metric_type":"er_wait_minutes","metric_value":35,"measured_at":"`+at+`"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, ingest(tokenFor(models.RoleUser)).Code)
+
+	mock.ExpectQuery("SELECT (.+) FROM facilities WHERE").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec("create_facility_metrics_partition").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO facility_metrics").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := ingest(tokenFor(models.RoleStaff))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"facility_id":"7","stored":1}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,67 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"server/internal/handlers"
+	"server/pkg/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func ok(context.Context) (string, error) { return "", nil }
+
+type schema struct{ applied, latest int64 }
+
+func (s schema) Version(context.Context) (int64, error) { return s.applied, nil }
+func (s schema) Latest() int64                          { return s.latest }
+
+func TestReadinessReportsEachDependency(t *testing.T) {
+	checker := health.NewChecker(20 * time.Millisecond)
+	checker.Add("database", ok)
+	checker.Add("migrations", health.MigrationVersion(schema{applied: 2, latest: 3}))
+	checker.Add("redis", health.Ping(func(ctx context.Context) error {
+		<-ctx.Done() // never answers
+		return errors.New("unreachable")
+	}))
+
+	report := checker.Check(context.Background())
+
+	assert.False(t, report.Ready)
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, "schema is at version 2, expected 3", report.Checks["migrations"].Error)
+	assert.Equal(t, health.StatusFailed, report.Checks["redis"].Status)
+	assert.Less(t, report.Checks["redis"].DurationMs, float64(time.Second.Milliseconds()))
+}
+
+func TestReadinessFailsDuringShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checker := health.NewChecker(time.Second)
+	checker.Add("migrations", health.MigrationVersion(schema{applied: 3, latest: 3}))
+
+	router := gin.New()
+	handlers.NewHealthHandler(checker).RegisterHealthRoutes(router)
+	probe := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	ready := probe("/readyz")
+	assert.Equal(t, http.StatusOK, ready.Code)
+	assert.Contains(t, ready.Body.String(), `"detail":"version 3"`)
+
+	checker.ShutDown()
+
+	draining := probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, draining.Code)
+	assert.Contains(t, draining.Body.String(), health.StatusShuttingDown)
+	assert.Equal(t, http.StatusOK, probe("/healthz").Code)
+}
@@ -0,0 +1,77 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"server/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactHidesPersonalDataAndCredentials(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(logger.Redact(core)).With(zap.String("user_email", "ali@example.com"))
+
+	log.Info("Registered",
+		zap.String("phone", "07701234567"),
+		zap.String("sessionToken", "abc"),
+		zap.String("Authorization", "Bearer abc"),
+		zap.String("user_id", "42"),
+	)
+
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, map[string]any{
+		"user_email":    logger.Redacted,
+		"phone":         logger.Redacted,
+		"sessionToken":  logger.Redacted,
+		"Authorization": logger.Redacted,
+		"user_id":       "42",
+	}, fields)
+}
+
+func TestRedactScrubsPersonalDataInValues(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(logger.Redact(core))
+
+	constraint := fmt.Errorf("create user: %w", errors.New(
+		`pq: duplicate key value violates unique constraint "users_email_key" Key (email)=(ali@example.com) already exists`))
+	log.Error("Registration failed for ali@example.com",
+		zap.Error(constraint),
+		zap.String("detail", "call +9647701234567 or 07701234567"),
+		zap.Any("user", struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Note  string `json:"note"`
+		}{"Ali", "ali@example.com", "reach me at ali@example.com"}),
+		zap.String("request_id", "1234567890123456789"),
+	)
+
+	entry := logs.All()[0]
+	assert.Equal(t, "Registration failed for "+logger.Redacted, entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, `create user: pq: duplicate key value violates unique constraint "users_email_key" Key (email)=([REDACTED]) already exists`, fields["error"])
+	assert.Equal(t, "call [REDACTED] or [REDACTED]", fields["detail"])
+	assert.Equal(t, map[string]any{"name": "Ali", "email": logger.Redacted, "note": "reach me at " + logger.Redacted}, fields["user"])
+	assert.Equal(t, "1234567890123456789", fields["request_id"], "IDs are not mistaken for phone numbers")
+}
+
+func TestFromContextCarriesFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger.SetLogger(zap.New(core))
+	t.Cleanup(func() { logger.SetLogger(zap.NewNop()) })
+
+	ctx := logger.NewContext(context.Background(), zap.String("request_id", "r-1"))
+	ctx = logger.NewContext(ctx, zap.String("user_id", "42"))
+	logger.FromContext(ctx).Info("Facility updated")
+	logger.FromContext(context.Background()).Info("Background job ran")
+
+	entries := logs.All()
+	assert.Equal(t, map[string]any{"request_id": "r-1", "user_id": "42"}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap(), "contexts without a logger get the global one")
+}
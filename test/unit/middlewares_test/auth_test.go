@@ -0,0 +1,65 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"server/internal/models"
+	"server/internal/services"
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleBasedAccessControlTrustsOnlyTheVerifiedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService(nil, []byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/admin", middlewares.AuthMiddleware(authService), middlewares.RoleBasedAccessControl("admin"),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tokenFor := func(role models.UserRole) string {
+		token, err := authService.GenerateAuthToken(&models.User{BaseModel: models.BaseModel{ID: 42}, Role: role})
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"admin token", map[string]string{"Authorization": tokenFor(models.RoleAdmin)}, http.StatusNoContent},
+		{"user token claiming admin", map[string]string{"Authorization": tokenFor(models.RoleUser), "X-User-Role": "admin"}, http.StatusForbidden},
+		{"staff token", map[string]string{"Authorization": tokenFor(models.RoleStaff)}, http.StatusForbidden},
+		{"no token", map[string]string{"X-User-Role": "admin"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, send(router, http.MethodGet, "/admin", tt.headers).Code)
+		})
+	}
+}
+
+func TestRoleBasedAccessControlAllowsAnyListedRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService(nil, []byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/staff", middlewares.AuthMiddleware(authService), middlewares.RoleBasedAccessControl("staff", "admin"),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for role, want := range map[models.UserRole]int{
+		models.RoleStaff: http.StatusNoContent,
+		models.RoleAdmin: http.StatusNoContent,
+		models.RoleUser:  http.StatusForbidden,
+	} {
+		token, err := authService.GenerateAuthToken(&models.User{BaseModel: models.BaseModel{ID: 42}, Role: role})
+		require.NoError(t, err)
+		assert.Equal(t, want, send(router, http.MethodGet, "/staff", map[string]string{"Authorization": token}).Code, role)
+	}
+}
@@ -0,0 +1,86 @@
+package middlewares_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"server/pkg/apperrors"
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveError answers a request whose handler fails with err.
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, apperrors.Problem) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.RequestID(), middlewares.ErrorHandler())
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(err)
+		c.Abort()
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	var problem apperrors.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return w, problem
+}
+
+func TestErrorHandlerRendersProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   apperrors.Kind
+	}{
+		{"not found", apperrors.NotFound("city not found"), http.StatusNotFound, apperrors.KindNotFound},
+		{"wrapped conflict", fmt.Errorf("create user: %w", apperrors.Conflict("user already exists")), http.StatusConflict, apperrors.KindConflict},
+		{"unauthorized", apperrors.Unauthorized("invalid credentials"), http.StatusUnauthorized, apperrors.KindUnauthorized},
+		{"forbidden", apperrors.Forbidden("Insufficient permissions"), http.StatusForbidden, apperrors.KindForbidden},
+		{"rate limited", apperrors.RateLimited("Too many requests", 1500*time.Millisecond), http.StatusTooManyRequests, apperrors.KindRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := serveError(t, tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, apperrors.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, "/fail", problem.Instance)
+			assert.Equal(t, w.Header().Get(middlewares.RequestIDHeader), problem.RequestID)
+		})
+	}
+}
+
+func TestErrorHandlerListsInvalidFields(t *testing.T) {
+	w, problem := serveError(t, apperrors.Validation("Invalid request data",
+		apperrors.FieldError{Field: "name", Message: "name is required"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []apperrors.FieldError{{Field: "name", Message: "name is required"}}, problem.Errors)
+}
+
+func TestErrorHandlerSetsRetryAfter(t *testing.T) {
+	w, _ := serveError(t, apperrors.RateLimited("Too many requests", 1500*time.Millisecond))
+
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	w, problem := serveError(t, errors.New("pq: password authentication failed for user \"postgres\""))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, apperrors.KindInternal, problem.Code)
+	assert.NotContains(t, w.Body.String(), "postgres")
+}
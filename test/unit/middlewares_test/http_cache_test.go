@@ -0,0 +1,123 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCachedRouter(routes map[string]time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.HTTPCache(0, routes), middlewares.ErrorHandler())
+	router.GET("/cities", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []gin.H{
+			{"name": "Najaf", "updated_at": "2026-03-01T10:00:00.123456Z"},
+			{"name": "Basra", "updated_at": "2026-03-02T08:30:00Z"},
+		})
+	})
+	router.GET("/cities/basra", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"city": gin.H{"name": "Basra", "updated_at": "2026-03-02T08:30:00Z"}})
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/versioned", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.String(http.StatusOK, "body")
+	})
+	return router
+}
+
+func get(router http.Handler, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHTTPCacheSetsValidatorsAndPolicy(t *testing.T) {
+	router := newCachedRouter(map[string]time.Duration{"GET /cities": time.Hour})
+
+	w := get(router, "/cities", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Basra")
+	assert.Regexp(t, `^"[A-Za-z0-9_-]{22}"$`, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Last-Modified"), "collections are validated by their ETag alone")
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+	w = get(router, "/cities/basra", nil)
+	assert.Equal(t, "Mon, 02 Mar 2026 08:30:00 GMT", w.Header().Get("Last-Modified"))
+
+	w = get(router, "/versioned", map[string]string{"Authorization": "Bearer token"})
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"), "handler ETags are kept")
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+}
+
+func TestHTTPCacheAnswersConditionalGETs(t *testing.T) {
+	router := newCachedRouter(nil)
+	etag := get(router, "/cities", nil).Header().Get("ETag")
+
+	w := get(router, "/cities", map[string]string{"If-None-Match": `"stale", W/` + etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = get(router, "/cities", map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": "Tue, 03 Mar 2026 00:00:00 GMT"})
+	assert.Equal(t, http.StatusOK, w.Code, "If-None-Match takes precedence over If-Modified-Since")
+
+	w = get(router, "/cities/basra", map[string]string{"If-Modified-Since": "Mon, 02 Mar 2026 08:30:00 GMT"})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = get(router, "/cities/basra", map[string]string{"If-Modified-Since": "Mon, 02 Mar 2026 08:29:59 GMT"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = get(router, "/cities", map[string]string{"If-Modified-Since": "Tue, 03 Mar 2026 00:00:00 GMT"})
+	assert.Equal(t, http.StatusOK, w.Code, "collections ignore If-Modified-Since")
+}
+
+func TestHTTPCachePassesErrorsThrough(t *testing.T) {
+	router := newCachedRouter(nil)
+
+	w := get(router, "/missing", map[string]string{"If-None-Match": "*"})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestHTTPCacheForwardsStatusWithoutBody(t *testing.T) {
+	router := newCachedRouter(nil)
+
+	w := get(router, "/empty", nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestHTTPCacheLetsRecoveryAnswerPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.Recovery(), middlewares.HTTPCache(0, nil), middlewares.ErrorHandler())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := get(router, "/panic", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"status":500`)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
@@ -0,0 +1,101 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"testing"
+
+	"server/pkg/logger"
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger.SetLogger(zap.New(core))
+	t.Cleanup(func() { logger.SetLogger(zap.NewNop()) })
+	return logs
+}
+
+func newLoggedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.RequestID(), middlewares.Logging("/healthz"))
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/facilities/:id", func(c *gin.Context) {
+		c.Set(middlewares.UserIDKey, "42")
+		logger.FromContext(c.Request.Context()).Info("Facility loaded")
+		c.String(http.StatusOK, "hello")
+	})
+	return router
+}
+
+func TestLoggingWritesCorrelatedAccessLog(t *testing.T) {
+	logs := observeLogs(t)
+
+	w := send(newLoggedRouter(), http.MethodGet, "/api/facilities/7?email=ali@example.com", map[string]string{middlewares.RequestIDHeader: "req-1"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-1", w.Header().Get(middlewares.RequestIDHeader))
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "req-1", entries[0].ContextMap()["request_id"], "handler lines carry the request ID")
+
+	access := entries[1].ContextMap()
+	assert.Equal(t, "req-1", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/api/facilities/:id", access["route"])
+	assert.Equal(t, "/api/facilities/7", access["path"], "the query string is not logged")
+	assert.EqualValues(t, http.StatusOK, access["status"])
+	assert.EqualValues(t, 5, access["bytes"])
+	assert.Equal(t, "42", access["user_id"])
+	assert.Contains(t, access, "latency")
+	assert.Contains(t, access, "client_ip")
+}
+
+func TestRequestIDReplacesImplausibleIDs(t *testing.T) {
+	observeLogs(t)
+	router := newLoggedRouter()
+
+	w := send(router, http.MethodGet, "/healthz", map[string]string{middlewares.RequestIDHeader: "evil\nInjected log line"})
+	assert.Len(t, w.Header().Get(middlewares.RequestIDHeader), 32)
+
+	w = send(router, http.MethodGet, "/healthz", nil)
+	assert.Len(t, w.Header().Get(middlewares.RequestIDHeader), 32)
+}
+
+func TestLoggingKeepsQuietPathsAtDebug(t *testing.T) {
+	logs := observeLogs(t)
+
+	send(newLoggedRouter(), http.MethodGet, "/healthz", nil)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, zapcore.DebugLevel, logs.All()[0].Level)
+}
+
+func TestRecoveryLogsPanicsInsideTheRequestChain(t *testing.T) {
+	logs := observeLogs(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.RequestID(), middlewares.Logging(), middlewares.Recovery())
+	router.GET("/api/facilities/:id", func(c *gin.Context) { panic("nil facility") })
+
+	w := send(router, http.MethodGet, "/api/facilities/7", map[string]string{middlewares.RequestIDHeader: "req-2"})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"request_id":"req-2"`)
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Request panicked", entries[0].Message)
+	assert.Equal(t, "req-2", entries[0].ContextMap()["request_id"])
+	assert.Equal(t, "nil facility", entries[0].ContextMap()["panic"])
+	assert.Contains(t, entries[0].ContextMap()["stack"], "runtime/panic")
+	assert.Equal(t, "Request served", entries[1].Message)
+	assert.EqualValues(t, http.StatusInternalServerError, entries[1].ContextMap()["status"])
+}
@@ -0,0 +1,86 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"server/pkg/middlewares"
+	"server/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+
+	api := router.Group("/api", middlewares.RateLimit(ratelimit.NewMemoryStore(), middlewares.RateLimitPolicy{
+		Default: ratelimit.Limit{Rate: 0.01, Burst: 3},
+		Routes: map[string]ratelimit.Limit{
+			"POST /api/login":            {Rate: 0.01, Burst: 1},
+			"GET /api/facilities/search": {},
+		},
+		UserOf: func(c *gin.Context) string {
+			if c.GetHeader("Authorization") == "Bearer valid" {
+				return "42"
+			}
+			return ""
+		},
+		APIKeys: []string{"partner-key"},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	api.POST("/login", ok)
+	api.GET("/cities/:id", ok)
+	api.GET("/facilities/search", ok)
+	return router
+}
+
+func send(router http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitRejectsWithRetryAfter(t *testing.T) {
+	router := newRateLimitedRouter()
+
+	w := send(router, http.MethodPost, "/api/login", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "100", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "1;w=100", w.Header().Get("RateLimit-Policy"))
+
+	w = send(router, http.MethodPost, "/api/login", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "100", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"code":"rate_limited"`)
+
+	// Other routes draw from the default bucket
+	assert.Equal(t, http.StatusNoContent, send(router, http.MethodGet, "/api/cities/1", nil).Code)
+}
+
+func TestRateLimitKeysByIdentity(t *testing.T) {
+	router := newRateLimitedRouter()
+	for i := 0; i < 3; i++ {
+		send(router, http.MethodGet, "/api/cities/1", nil)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send(router, http.MethodGet, "/api/cities/1", nil).Code)
+
+	// The user, and the holder of a known API key, are limited apart from their IP
+	assert.Equal(t, http.StatusNoContent, send(router, http.MethodGet, "/api/cities/1", map[string]string{"Authorization": "Bearer valid"}).Code)
+	assert.Equal(t, http.StatusNoContent, send(router, http.MethodGet, "/api/cities/1", map[string]string{middlewares.APIKeyHeader: "partner-key"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, send(router, http.MethodGet, "/api/cities/1", map[string]string{middlewares.APIKeyHeader: "made-up"}).Code)
+
+	// Unlimited routes carry no headers
+	w := send(router, http.MethodGet, "/api/facilities/search", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
@@ -0,0 +1,79 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"server/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSecureRouter(policy middlewares.SecurityPolicy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.CORS(middlewares.CORSPolicy{
+		AllowedOrigins: []string{"https://mydoctor.iq"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		ExposedHeaders: []string{"ETag"},
+		MaxAge:         time.Hour,
+	}))
+	router.Use(middlewares.SecurityHeaders(policy))
+	router.GET("/api/cities/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return router
+}
+
+func TestCORSAllowsOnlyConfiguredOrigins(t *testing.T) {
+	router := newSecureRouter(middlewares.SecurityPolicy{})
+
+	preflight := send(router, http.MethodOptions, "/api/cities/1", map[string]string{
+		"Origin":                         "https://mydoctor.iq",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "Authorization",
+	})
+	assert.Equal(t, http.StatusNoContent, preflight.Code)
+	assert.Equal(t, "https://mydoctor.iq", preflight.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET,POST", preflight.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "3600", preflight.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, preflight.Header().Get("Access-Control-Allow-Credentials"))
+
+	w := send(router, http.MethodGet, "/api/cities/1", map[string]string{"Origin": "https://mydoctor.iq"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, strings.EqualFold("ETag", w.Header().Get("Access-Control-Expose-Headers")))
+
+	w = send(router, http.MethodGet, "/api/cities/1", map[string]string{"Origin": "https://evil.example"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	router := newSecureRouter(middlewares.SecurityPolicy{
+		ContentSecurityPolicy: "default-src 'none';",
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		FrameDeny:             true,
+		ReferrerPolicy:        "no-referrer",
+	})
+
+	w := send(router, http.MethodGet, "/api/cities/1", nil)
+	assert.Equal(t, "default-src 'none'; report-uri /csp-report", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"), "HSTS is only sent over HTTPS")
+
+	w = send(router, http.MethodGet, "/api/cities/1", map[string]string{"X-Forwarded-Proto": "https"})
+	assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeadersReportOnlyCSP(t *testing.T) {
+	router := newSecureRouter(middlewares.SecurityPolicy{ContentSecurityPolicy: "default-src 'self'", CSPReportOnly: true})
+
+	w := send(router, http.MethodGet, "/api/cities/1", nil)
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "default-src 'self'; report-uri /csp-report", w.Header().Get("Content-Security-Policy-Report-Only"))
+}
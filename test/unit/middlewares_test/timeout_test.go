@@ -0,0 +1,81 @@
+package middlewares_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"server/internal/handlers"
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/pkg/middlewares"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slowQuery = 500 * time.Millisecond
+
+// newCityRouter serves the city routes from a mocked database whose Find query takes slowQuery.
+func newCityRouter(t *testing.T, timeout time.Duration, routes map[string]time.Duration) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	mock.ExpectQuery("SELECT (.+) FROM cities WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillDelayFor(slowQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Baghdad"))
+
+	repo := repositories.NewCitiesRepository(sqlx.NewDb(mockDB, "postgres"))
+	router := gin.New()
+	router.Use(middlewares.Timeout(timeout, routes), middlewares.ErrorHandler())
+	handlers.NewCityHandler(services.NewCityService(repo)).RegisterCityRoutes(router.Group("/api"))
+	return router, mock
+}
+
+func TestTimeoutReturns504WhenQueryExceedsDeadline(t *testing.T) {
+	router, mock := newCityRouter(t, 50*time.Millisecond, nil)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/cities/1", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Less(t, time.Since(start), slowQuery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelledRequestAbortsSlowQuery(t *testing.T) {
+	router, mock := newCityRouter(t, 0, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/cities/1", nil).WithContext(ctx))
+
+	assert.Equal(t, middlewares.StatusClientClosedRequest, w.Code)
+	assert.Less(t, time.Since(start), slowQuery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRouteTimeoutOverridesDefault(t *testing.T) {
+	router, mock := newCityRouter(t, 50*time.Millisecond, map[string]time.Duration{
+		"GET /api/cities/:id": 0, // No deadline for this route
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/cities/1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
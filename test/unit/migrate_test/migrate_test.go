@@ -0,0 +1,95 @@
+package migrate_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"server/db/migrations"
+	"server/pkg/migrate"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t(a);")},
+		"0002_add_index.down.sql":    {Data: []byte("DROP INDEX idx;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a INT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                  {Data: []byte("ignored")},
+	}
+}
+
+func TestLoadSortsByVersion(t *testing.T) {
+	loaded, err := migrate.Load(testFS())
+
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, int64(1), loaded[0].Version)
+	assert.Equal(t, "create_table", loaded[0].Name)
+	assert.Equal(t, "DROP TABLE t;", loaded[0].Down)
+	assert.Equal(t, int64(2), loaded[1].Version)
+}
+
+func TestLoadRequiresDownFile(t *testing.T) {
+	fsys := testFS()
+	delete(fsys, "0002_add_index.down.sql")
+
+	_, err := migrate.Load(fsys)
+
+	require.Error(t, err)
+}
+
+func TestEmbeddedMigrationsAreComplete(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	assert.Equal(t, int64(1), loaded[0].Version)
+	for i := 1; i < len(loaded); i++ {
+		assert.Greater(t, loaded[i].Version, loaded[i-1].Version)
+	}
+}
+
+func TestUpAppliesPendingMigrationsUnderLock(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db := sqlx.NewDb(mockDB, "postgres")
+	defer db.Close()
+
+	migrator, err := migrate.New(db, testFS())
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx ON t(a);")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(2), "add_index").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, migrator.Up(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestToRejectsUnknownVersion(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	db := sqlx.NewDb(mockDB, "postgres")
+	defer db.Close()
+
+	migrator, err := migrate.New(db, testFS())
+	require.NoError(t, err)
+
+	err = migrator.To(context.Background(), 42)
+
+	assert.ErrorIs(t, err, migrate.ErrNoSuchVersion)
+}
@@ -0,0 +1,156 @@
+package monitoring_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	_ "server/internal/services" // registers the business metrics
+	"server/pkg/sqlinstrument"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type ruleFile struct {
+	Groups []struct {
+		Name  string `yaml:"name"`
+		Rules []struct {
+			Alert string `yaml:"alert"`
+			Expr  string `yaml:"expr"`
+		} `yaml:"rules"`
+	} `yaml:"groups"`
+}
+
+var (
+	// selector matches a metric name and its optional label matchers
+	selector = regexp.MustCompile(`\b([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(\{[^}]*\})?`)
+	// grouping matches the label lists of aggregations and vector matching
+	grouping = regexp.MustCompile(`\b(by|without|on|ignoring|group_left|group_right)\s*\([^)]*\)`)
+	// matcherLabel matches the label name of a label matcher such as status="failure"
+	matcherLabel = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)`)
+	// rangeOrString matches range selectors and string literals, which name no metrics
+	rangeOrString = regexp.MustCompile(`\[[^\]]*\]|"[^"]*"`)
+)
+
+// keywords are PromQL words that read like metric names.
+var keywords = map[string]bool{"and": true, "or": true, "unless": true, "bool": true, "offset": true}
+
+// metricSelectors returns the metrics expr selects, with the labels it matches on.
+func metricSelectors(expr string) map[string][]string {
+	expr = grouping.ReplaceAllString(rangeOrString.ReplaceAllString(expr, `""`), "")
+	selectors := map[string][]string{}
+	for _, loc := range selector.FindAllStringSubmatchIndex(expr, -1) {
+		name := expr[loc[2]:loc[3]]
+		rest := strings.TrimLeft(expr[loc[1]:], " \t\n")
+		if keywords[name] || strings.HasPrefix(rest, "(") || (loc[4] < 0 && isInsideBraces(expr, loc[2])) {
+			continue
+		}
+		labels := selectors[name]
+		if loc[4] >= 0 {
+			for _, m := range matcherLabel.FindAllStringSubmatch(expr[loc[4]:loc[5]], -1) {
+				labels = append(labels, m[1])
+			}
+		}
+		selectors[name] = labels
+	}
+	return selectors
+}
+
+func isInsideBraces(expr string, at int) bool {
+	return strings.LastIndex(expr[:at], "{") > strings.LastIndex(expr[:at], "}")
+}
+
+// exportedMetrics gathers the metrics the server exports, keyed by the names PromQL selects them
+// by, with the labels each carries.
+func exportedMetrics(t *testing.T) map[string]map[string]bool {
+	// Query metrics only appear once a query has run
+	dsn := "monitoring_test/" + t.Name()
+	_, mock, err := sqlmock.NewWithDSN(dsn)
+	require.NoError(t, err)
+	mock.ExpectQuery("SELECT id FROM facilities").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("SELECT id FROM facilities").WillReturnError(context.DeadlineExceeded)
+	sqlDB, err := sqlinstrument.Open("sqlmock", dsn)
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	for i := 0; i < 2; i++ {
+		if rows, err := sqlDB.QueryContext(context.Background(), "SELECT id FROM facilities"); err == nil {
+			rows.Close()
+		}
+	}
+
+	pool := prometheus.NewRegistry()
+	pool.MustRegister(collectors.NewDBStatsCollector(sqlDB, "app"))
+	families, err := prometheus.Gatherers{prometheus.DefaultGatherer, pool}.Gather()
+	require.NoError(t, err)
+
+	metrics := map[string]map[string]bool{}
+	add := func(name string, family *dto.MetricFamily, extra ...string) {
+		labels := map[string]bool{"__name__": true}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = true
+			}
+		}
+		for _, label := range extra {
+			labels[label] = true
+		}
+		metrics[name] = labels
+	}
+	for _, family := range families {
+		name := family.GetName()
+		add(name, family)
+		switch family.GetType() {
+		case dto.MetricType_HISTOGRAM:
+			add(name+"_bucket", family, "le")
+			add(name+"_sum", family)
+			add(name+"_count", family)
+		case dto.MetricType_SUMMARY:
+			add(name+"_sum", family)
+			add(name+"_count", family)
+		}
+	}
+	return metrics
+}
+
+func TestAlertRulesSelectExportedMetrics(t *testing.T) {
+	data, err := os.ReadFile("../../../alert_rules.yml")
+	require.NoError(t, err)
+	var rules ruleFile
+	require.NoError(t, yaml.Unmarshal(data, &rules))
+	require.NotEmpty(t, rules.Groups)
+
+	metrics := exportedMetrics(t)
+	for _, group := range rules.Groups {
+		for _, rule := range group.Rules {
+			selectors := metricSelectors(rule.Expr)
+			assert.NotEmpty(t, selectors, "%s selects no metric", rule.Alert)
+			for name, labels := range selectors {
+				exported, ok := metrics[name]
+				if !assert.True(t, ok, "%s selects %s, which is not exported", rule.Alert, name) {
+					continue
+				}
+				for _, label := range labels {
+					assert.True(t, exported[label], "%s matches on %s, which %s does not carry", rule.Alert, label, name)
+				}
+			}
+		}
+	}
+}
+
+func TestMetricSelectorsSkipsFunctionsAndLabels(t *testing.T) {
+	selectors := metricSelectors(`sum by (table) (rate(database_query_total{status="failure", table=~"a|b"}[5m])) / (go_sql_max_open_connections > 0) and on (db_name) up`)
+
+	assert.Equal(t, map[string][]string{
+		"database_query_total":        {"status", "table"},
+		"go_sql_max_open_connections": nil,
+		"up":                          nil,
+	}, selectors)
+}
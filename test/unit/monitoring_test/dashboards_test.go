@@ -0,0 +1,72 @@
+package monitoring_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dashboard struct {
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	Panels []struct {
+		Title   string `json:"title"`
+		Targets []struct {
+			Datasource struct {
+				UID string `json:"uid"`
+			} `json:"datasource"`
+			Expr string `json:"expr"`
+		} `json:"targets"`
+	} `json:"panels"`
+}
+
+// registered reports whether the default registry has a collector describing the metric name, even
+// one that has not exported a series yet: registering another metric of that name fails then.
+func registered(name string) bool {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok && registered(base) {
+			return true
+		}
+	}
+	probe := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: "probe"})
+	if err := prometheus.Register(probe); err != nil {
+		return true
+	}
+	prometheus.Unregister(probe)
+	return false
+}
+
+func TestDashboardsQueryRegisteredMetrics(t *testing.T) {
+	paths, err := filepath.Glob("../../../grafana/dashboards/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	metrics := exportedMetrics(t)
+	uids := map[string]bool{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var d dashboard
+		require.NoError(t, json.Unmarshal(data, &d), path)
+		assert.NotEmpty(t, d.UID, path)
+		assert.False(t, uids[d.UID], "%s reuses the dashboard UID %s", path, d.UID)
+		uids[d.UID] = true
+
+		for _, panel := range d.Panels {
+			require.NotEmpty(t, panel.Targets, "%s: %s has no queries", d.Title, panel.Title)
+			for _, target := range panel.Targets {
+				assert.Equal(t, "prometheus", target.Datasource.UID, "%s: %s", d.Title, panel.Title)
+				for name := range metricSelectors(target.Expr) {
+					_, exported := metrics[name]
+					assert.True(t, exported || registered(name), "%s: %s queries %s, which is not registered", d.Title, panel.Title, name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"server/pkg/ratelimit"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slow refills a token every 100 seconds, so tests never see a refill.
+var slow = ratelimit.Limit{Rate: 0.01, Burst: 2}
+
+func assertDrainsBucket(t *testing.T, store ratelimit.Store) {
+	ctx := context.Background()
+
+	first, err := store.Take(ctx, "login:ip:10.0.0.1", slow)
+	require.NoError(t, err)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.InDelta(t, 100*time.Second, first.Reset, float64(time.Second))
+
+	second, err := store.Take(ctx, "login:ip:10.0.0.1", slow)
+	require.NoError(t, err)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+
+	rejected, err := store.Take(ctx, "login:ip:10.0.0.1", slow)
+	require.NoError(t, err)
+	assert.False(t, rejected.Allowed)
+	assert.InDelta(t, 100*time.Second, rejected.RetryAfter, float64(time.Second))
+
+	other, err := store.Take(ctx, "login:ip:10.0.0.2", slow)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "buckets are per key")
+}
+
+func TestMemoryStoreDrainsBucket(t *testing.T) {
+	assertDrainsBucket(t, ratelimit.NewMemoryStore())
+}
+
+func TestRedisStoreDrainsBucket(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	assertDrainsBucket(t, ratelimit.NewRedisStore(client, failingStore{}))
+	assert.True(t, server.Exists("ratelimit:login:ip:10.0.0.1"))
+}
+
+func TestRedisStoreSharesBucketsBetweenInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+	limit := ratelimit.Limit{Rate: 0.01, Burst: 1}
+
+	for i, wantAllowed := range []bool{true, false} {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		result, err := ratelimit.NewRedisStore(client, failingStore{}).Take(ctx, "search:user:7", limit)
+		client.Close()
+
+		require.NoError(t, err)
+		assert.Equal(t, wantAllowed, result.Allowed, "instance %d", i)
+	}
+}
+
+func TestRedisStoreFallsBackWhenRedisFails(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	server.Close()
+
+	result, err := ratelimit.NewRedisStore(client, ratelimit.NewMemoryStore()).Take(context.Background(), "key", slow)
+
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("fallback used")
+}
@@ -0,0 +1,89 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"server/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogQueryBuildsFilters(t *testing.T) {
+	recordID, changedBy := int64(1234567890123456789), int64(42)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name  string
+		query repositories.AuditLogQuery
+		where string
+		args  []driver.Value
+	}{
+		{
+			name:  "no filters pages with the default limit",
+			query: repositories.AuditLogQuery{},
+			where: `SELECT * FROM audit_log ORDER BY changed_at DESC, id DESC LIMIT $1 OFFSET $2`,
+			args:  []driver.Value{int64(100), int64(0)},
+		},
+		{
+			name:  "table and record ID match the id key of either snapshot",
+			query: repositories.AuditLogQuery{TableName: "facilities", RecordID: &recordID, Limit: 10, Offset: 20},
+			where: `WHERE table_name = $1 AND COALESCE(new_data->>'id', old_data->>'id') = $2 ORDER BY changed_at DESC, id DESC LIMIT $3 OFFSET $4`,
+			args:  []driver.Value{"facilities", "1234567890123456789", int64(10), int64(20)},
+		},
+		{
+			name:  "limits above the maximum are capped",
+			query: repositories.AuditLogQuery{Limit: 10000000},
+			where: `SELECT * FROM audit_log ORDER BY changed_at DESC, id DESC LIMIT $1 OFFSET $2`,
+			args:  []driver.Value{int64(500), int64(0)},
+		},
+		{
+			name:  "operation is upper-cased and field matches rows where it changed",
+			query: repositories.AuditLogQuery{Operation: "update", Field: "name"},
+			where: `WHERE operation = $1 AND (old_data->$2::text) IS DISTINCT FROM (new_data->$2::text) ORDER BY`,
+			args:  []driver.Value{"UPDATE", "name", int64(100), int64(0)},
+		},
+		{
+			name:  "actor, request and time range",
+			query: repositories.AuditLogQuery{ChangedBy: &changedBy, RequestID: "req-1", From: &from, To: &to},
+			where: `WHERE changed_by = $1 AND request_id = $2 AND changed_at >= $3 AND changed_at <= $4 ORDER BY`,
+			args:  []driver.Value{changedBy, "req-1", from, to, int64(100), int64(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock := newMockDB(t)
+			mock.ExpectQuery(regexp.QuoteMeta(tt.where)).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			_, err := repositories.NewAuditLogRepository(mockDB).Query(context.Background(), tt.query)
+
+			require.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetAuditActorTruncatesLongRequestIDs(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	userID := int64(42)
+	requestID := strings.Repeat("a", 100)
+
+	mock.ExpectExec("set_config").
+		WithArgs("42", requestID[:64], "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repositories.SetAuditActor(context.Background(), mockDB, repositories.Actor{UserID: &userID, RequestID: requestID})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
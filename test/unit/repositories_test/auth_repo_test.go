@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"context"
 	"server/internal/models"
 	"server/internal/repositories"
 	"testing"
@@ -43,7 +44,7 @@ func TestCreateUser(t *testing.T) {
 		Password: "tewdhhjcg",
 	}
 
-	result, err := repo.CreateUser(user)
+	result, err := repo.CreateUser(context.Background(), user)
 
 	require.NoError(t, err)
 	assert.Equal(t, "John Doe", result.Name)
@@ -60,10 +61,10 @@ func TestGetUser(t *testing.T) {
 		Email: "john.doe@example.com",
 		Image: "image.png",
 	}
-	createdUser, err := repo.CreateUser(user)
+	createdUser, err := repo.CreateUser(context.Background(), user)
 	require.NoError(t, err)
 
-	fetchedUser, err := repo.GetUser(int(createdUser.ID))
+	fetchedUser, err := repo.GetUser(context.Background(), int(createdUser.ID))
 
 	require.NoError(t, err)
 	assert.Equal(t, "John Doe", fetchedUser.Name)
@@ -80,12 +81,12 @@ func TestUpdateUser(t *testing.T) {
 		Email: "john.doe@example.com",
 		Image: "image.png",
 	}
-	createdUser, err := repo.CreateUser(user)
+	createdUser, err := repo.CreateUser(context.Background(), user)
 	require.NoError(t, err)
 
 	createdUser.Name = "John Updated"
 	createdUser.Email = "john.updated@example.com"
-	updatedUser, err := repo.UpdateUser(createdUser)
+	updatedUser, err := repo.UpdateUser(context.Background(), createdUser)
 
 	require.NoError(t, err)
 	assert.Equal(t, "John Updated", updatedUser.Name)
@@ -102,14 +103,14 @@ func TestDeleteUser(t *testing.T) {
 		Email: "john.doe@example.com",
 		Image: "image.png",
 	}
-	createdUser, err := repo.CreateUser(user)
+	createdUser, err := repo.CreateUser(context.Background(), user)
 	require.NoError(t, err)
 
-	err = repo.DeleteUser(int(createdUser.ID))
+	err = repo.DeleteUser(context.Background(), int(createdUser.ID))
 
 	require.NoError(t, err)
 
-	_, err = repo.GetUser(int(createdUser.ID))
+	_, err = repo.GetUser(context.Background(), int(createdUser.ID))
 	require.Error(t, err)
 }
 
@@ -127,10 +128,10 @@ func TestGetUserByEmailOrPhone(t *testing.T) {
 		}(),
 		Image: "image.png",
 	}
-	_, err := repo.CreateUser(user)
+	_, err := repo.CreateUser(context.Background(), user)
 	require.NoError(t, err)
 
-	fetchedUser, err := repo.GetUserByEmailOrPhone("john.doe@example.com")
+	fetchedUser, err := repo.GetUserByEmailOrPhone(context.Background(), "john.doe@example.com")
 
 	require.NoError(t, err)
 	assert.Equal(t, "John Doe", fetchedUser.Name)
@@ -147,7 +148,7 @@ func TestCleanup(t *testing.T) {
 		Email: "jane.doe@example.com",
 		Image: "image2.png",
 	}
-	_, err := repo.CreateUser(user)
+	_, err := repo.CreateUser(context.Background(), user)
 	require.NoError(t, err)
 
 	tearDownDB(t)
@@ -0,0 +1,62 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedRepositoryInvalidatesOnCommitOnly(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+	repo := repositories.NewCachedCitiesRepository(repositories.NewCitiesRepository(mockDB),
+		cache.New[models.City]("cities_test", nil, cache.Options{LocalSize: 10, LocalTTL: time.Minute, TTL: time.Minute}))
+	ctx := context.Background()
+	find := regexp.QuoteMeta(`SELECT ` + cityColumns + ` FROM cities WHERE id = $1`)
+
+	mock.ExpectQuery(find).WillReturnRows(cityRow(7, "Najaf"))
+	for i := 0; i < 2; i++ {
+		city, err := repo.Find(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, "Najaf", city.Name)
+	}
+
+	// A rolled back write leaves the cached entry in place
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE cities").WillReturnRows(cityRow(7, "Kufa"))
+	mock.ExpectRollback()
+	err := transactor.WithinTransaction(ctx, repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		if _, err := uow.Cities().Update(ctx, 7, map[string]interface{}{"name": "Kufa"}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	city, err := repo.Find(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Najaf", city.Name)
+
+	// A committed one drops it
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE cities").WillReturnRows(cityRow(7, "An Najaf"))
+	mock.ExpectCommit()
+	require.NoError(t, transactor.WithinTransaction(ctx, repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		_, err := uow.Cities().Update(ctx, 7, map[string]interface{}{"name": "An Najaf"})
+		return err
+	}))
+	mock.ExpectQuery(find).WillReturnRows(cityRow(7, "An Najaf"))
+	city, err = repo.Find(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "An Najaf", city.Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,75 @@
+package repositories_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFacilityMetricsInsertWritesBatchInOneStatement(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewFacilityMetricsRepository(mockDB)
+	measuredAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("AST", 3*60*60))
+
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT * FROM unnest($1::bigint[], $2::facility_metric_type[], $3::double precision[], $4::timestamptz[])`)).
+		WithArgs("{7,7}", `{"bed_occupancy","er_wait_minutes"}`, "{87.5,35}",
+			`{"2024-05-01T06:30:00Z","2024-05-01T06:30:00Z"}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	stored, err := repo.Insert(context.Background(), []models.FacilityMetrics{
+		{FacilityID: 7, MetricType: models.BedOccupancy, MetricValue: 87.5, MeasuredAt: measuredAt},
+		{FacilityID: 7, MetricType: models.ERWaitMinutes, MetricValue: 35, MeasuredAt: measuredAt},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacilityMetricsBucketsAlignsToRangeStart(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewFacilityMetricsRepository(mockDB)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(6 * time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`date_bin(make_interval(secs => $4::double precision), measured_at, $2::timestamptz) AS bucket_start`)+
+		`(.+)`+regexp.QuoteMeta(`WHERE facility_id = $1 AND measured_at >= $2 AND measured_at < $3 AND metric_type = $5`)).
+		WithArgs(int64(7), from, to, float64(900), "er_wait_minutes").
+		WillReturnRows(sqlmock.NewRows([]string{"metric_type", "bucket_start", "avg", "min", "max", "count"}).
+			AddRow("er_wait_minutes", from, 30.0, 20.0, 45.0, 3))
+
+	buckets, err := repo.Buckets(context.Background(), repositories.FacilityMetricsQuery{
+		FacilityID: 7, Type: models.ERWaitMinutes, From: from, To: to, Bucket: 15 * time.Minute,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacilityMetricBucket{
+		{MetricType: models.ERWaitMinutes, Start: from, Avg: 30, Min: 20, Max: 45, Count: 3},
+	}, buckets)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacilityMetricsDropsPartitionsOfDaysBeforeCutoff(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewFacilityMetricsRepository(mockDB)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE pg_inherits.inhparent = 'facility_metrics'::regclass`)).
+		WithArgs("facility_metrics_p", "facility_metrics_p20240501").
+		WillReturnRows(sqlmock.NewRows([]string{"relname"}).AddRow("facility_metrics_p20240429").AddRow("facility_metrics_p20240430"))
+	mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS "facility_metrics_p20240429"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS "facility_metrics_p20240430"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	dropped, err := repo.DropPartitionsBefore(context.Background(), time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"facility_metrics_p20240429", "facility_metrics_p20240430"}, dropped)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
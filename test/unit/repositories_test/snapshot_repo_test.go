@@ -0,0 +1,43 @@
+package repositories_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"server/internal/models"
+	"server/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotUpsertRestoresDeletedAtAndAdvancesVersion(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	row := models.JSONMap{
+		"id":         float64(7),
+		"name":       "Al-Kindi Hospital",
+		"created_at": "2024-01-01T00:00:00Z",
+		"updated_at": "2024-05-01T10:00:00Z",
+		"deleted_at": nil,
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`ON CONFLICT (id) DO UPDATE SET "deleted_at" = EXCLUDED."deleted_at", "name" = EXCLUDED."name", ` +
+		`updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond')`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repositories.NewSnapshotRepository(mockDB).Upsert(context.Background(), "facilities", row)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSnapshotLockRejectsTablesThatCannotBeRestored(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+
+	err := repositories.NewSnapshotRepository(mockDB).Lock(context.Background(), "users", 7)
+
+	require.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,118 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"server/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reviewRow(id int64, deletedAt *time.Time) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows([]string{"id", "created_at", "updated_at", "entity_type", "entity_id", "user_id", "rating", "comment", "deleted_at"}).
+		AddRow(id, now, now, "facility", int64(1), nil, nil, nil, deletedAt)
+}
+
+func TestSoftDeleteHidesTrashedRows(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewReviewsRepository(mockDB)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM reviews WHERE entity_id = $1 AND deleted_at IS NULL ORDER BY id`)).
+		WithArgs(int64(1)).
+		WillReturnRows(reviewRow(1, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM reviews WHERE entity_id = $1 ORDER BY id`)).
+		WithArgs(int64(1)).
+		WillReturnRows(reviewRow(1, nil))
+
+	_, err := repo.FindMany(context.Background(), map[string]interface{}{"entity_id": int64(1)})
+	require.NoError(t, err)
+
+	_, err = repo.FindMany(repositories.WithDeleted(context.Background()), map[string]interface{}{"entity_id": int64(1)})
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSoftDeleteMovesRowsToTrash(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewReviewsRepository(mockDB)
+	deletedAt := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE reviews SET deleted_at = now(), ` +
+		`updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond') WHERE id = $1 AND deleted_at IS NULL`)).
+		WithArgs(int64(4)).
+		WillReturnRows(reviewRow(4, &deletedAt))
+
+	review, err := repo.Delete(context.Background(), 4)
+
+	require.NoError(t, err)
+	assert.NotNil(t, review.DeletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSoftDeleteRejectsUpdatesOfDeletedAt(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewReviewsRepository(mockDB)
+
+	_, err := repo.Update(context.Background(), 4, map[string]interface{}{"deleted_at": nil})
+
+	assert.ErrorIs(t, err, repositories.ErrUnknownColumn)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSoftDeleteRestore(t *testing.T) {
+	restore := regexp.QuoteMeta(`UPDATE reviews SET deleted_at = NULL, ` +
+		`updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond') WHERE id = $1 AND deleted_at IS NOT NULL`)
+
+	t.Run("trashed row is restored", func(t *testing.T) {
+		mockDB, mock := newMockDB(t)
+		repo := repositories.NewReviewsRepository(mockDB)
+
+		mock.ExpectQuery(restore).WithArgs(int64(4)).WillReturnRows(reviewRow(4, nil))
+
+		review, err := repo.Restore(context.Background(), 4)
+
+		require.NoError(t, err)
+		assert.Nil(t, review.DeletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("row outside the trash is not found", func(t *testing.T) {
+		mockDB, mock := newMockDB(t)
+		repo := repositories.NewReviewsRepository(mockDB)
+
+		mock.ExpectQuery(restore).WithArgs(int64(4)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := repo.Restore(context.Background(), 4)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSoftDeletePurgeSkipsReferencedRows(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewDoctorRepository(mockDB)
+	before := time.Now().Add(-30 * 24 * time.Hour)
+	purge := regexp.QuoteMeta(`DELETE FROM doctors WHERE id = $1 AND deleted_at < $2`)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM doctors WHERE deleted_at < $1 ORDER BY id`)).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(2)))
+	mock.ExpectExec(purge).WithArgs(int64(1), before).WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectExec(purge).WithArgs(int64(2), before).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	purged, err := repo.Purge(context.Background(), before)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,231 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/sqlinstrument"
+	"server/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cityColumns = "id, created_at, updated_at, name, population, image_url, timezone"
+
+func TestMain(m *testing.M) {
+	node, err := utils.NewSnowflakeNode(0, 1, nil)
+	if err != nil {
+		panic(err)
+	}
+	utils.SetDefaultSnowflakeNode(node)
+	os.Exit(m.Run())
+}
+
+// newMockDB returns a mock database behind the instrumented driver, as utils.NewDB connects.
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	dsn := "repositories_test/" + t.Name()
+	_, mock, err := sqlmock.NewWithDSN(dsn)
+	require.NoError(t, err)
+	sqlDB, err := sqlinstrument.Open("sqlmock", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlx.NewDb(sqlDB, "postgres"), mock
+}
+
+func cityRow(id int64, name string) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "population", "image_url", "timezone"}).
+		AddRow(id, now, now, name, nil, nil, nil)
+}
+
+func TestSQLRepositoryCreateDerivesColumnsFromModel(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewCitiesRepository(mockDB)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO cities (id, name, population, image_url, timezone) VALUES ($1, $2, $3, $4, $5) RETURNING `+cityColumns)).
+		WithArgs(sqlmock.AnyArg(), "Baghdad", nil, nil, nil).
+		WillReturnRows(cityRow(1, "Baghdad"))
+
+	city, err := repo.Create(ctx, &models.City{Name: "Baghdad"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), city.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepositoryCreateAssignsSnowflakeIDs(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewCitiesRepository(mockDB)
+	ctx := context.Background()
+
+	var assigned int64
+	mock.ExpectQuery("INSERT INTO cities").
+		WithArgs(snowflakeArg{node: 1, id: &assigned}, "Kirkuk", nil, nil, nil).
+		WillReturnRows(cityRow(1, "Kirkuk"))
+	mock.ExpectQuery("INSERT INTO cities").
+		WithArgs(int64(42), "Halabja", nil, nil, nil).
+		WillReturnRows(cityRow(42, "Halabja"))
+
+	_, err := repo.Create(ctx, &models.City{Name: "Kirkuk"})
+	require.NoError(t, err)
+	assert.NotZero(t, assigned)
+
+	// An ID set by the caller, e.g. when restoring a row, is kept
+	_, err = repo.Create(ctx, &models.City{BaseModel: models.BaseModel{ID: 42}, Name: "Halabja"})
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// snowflakeArg matches a Snowflake ID generated by the given machine and records it.
+type snowflakeArg struct {
+	node int64
+	id   *int64
+}
+
+func (a snowflakeArg) Match(v driver.Value) bool {
+	id, ok := v.(int64)
+	if !ok || utils.DecodeSnowflakeID(id).MachineID != a.node {
+		return false
+	}
+	*a.id = id
+	return true
+}
+
+func TestSQLRepositoryCreateManyInsertsInsideTransaction(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewCitiesRepository(mockDB)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WithArgs(sqlmock.AnyArg(), "Basra", nil, nil, nil).WillReturnRows(cityRow(1, "Basra"))
+	mock.ExpectQuery("INSERT INTO cities").WithArgs(sqlmock.AnyArg(), "Erbil", nil, nil, nil).WillReturnRows(cityRow(2, "Erbil"))
+	mock.ExpectCommit()
+
+	cities, err := repo.CreateMany(ctx, []models.City{{Name: "Basra"}, {Name: "Erbil"}})
+
+	require.NoError(t, err)
+	require.Len(t, cities, 2)
+	assert.Equal(t, int64(2), cities[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepositoryCreateManyRollsBackOnFailure(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewCitiesRepository(mockDB)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(1, "Basra"))
+	mock.ExpectQuery("INSERT INTO cities").WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+
+	_, err := repo.CreateMany(ctx, []models.City{{Name: "Basra"}, {Name: "Basra"}})
+
+	require.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepositoryUpdateBumpsUpdatedAt(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewCitiesRepository(mockDB)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`UPDATE cities SET name = $1, population = $2, updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond') WHERE id = $3 RETURNING `+cityColumns)).
+		WithArgs("Mosul", 1500000, int64(7)).
+		WillReturnRows(cityRow(7, "Mosul"))
+
+	city, err := repo.Update(ctx, 7, map[string]interface{}{"population": 1500000, "name": "Mosul"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Mosul", city.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepositoryRejectsUnknownColumns(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	repo := repositories.NewCitiesRepository(mockDB)
+	ctx := context.Background()
+
+	_, err := repo.FindMany(ctx, map[string]interface{}{"name; DROP TABLE cities": "x"})
+	assert.ErrorIs(t, err, repositories.ErrUnknownColumn)
+
+	for _, column := range []string{"id", "created_at", "updated_at"} {
+		_, err = repo.Update(ctx, 1, map[string]interface{}{column: 2})
+		assert.ErrorIs(t, err, repositories.ErrUnknownColumn, column)
+	}
+
+	_, err = repo.DeleteMany(ctx, map[string]interface{}{})
+	assert.ErrorIs(t, err, repositories.ErrEmptyFilter)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepositoryCompareAndUpdate(t *testing.T) {
+	version := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	update := regexp.QuoteMeta(`UPDATE cities SET name = $1, ` +
+		`updated_at = GREATEST(clock_timestamp(), updated_at + interval '1 microsecond') WHERE id = $2 AND updated_at = $3`)
+	exists := regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM cities WHERE id = $1)`)
+
+	t.Run("matching version updates the row", func(t *testing.T) {
+		mockDB, mock := newMockDB(t)
+		repo := repositories.NewCitiesRepository(mockDB)
+
+		mock.ExpectQuery(update).WithArgs("Duhok", int64(3), version).WillReturnRows(cityRow(3, "Duhok"))
+
+		city, err := repo.CompareAndUpdate(context.Background(), 3, version, map[string]interface{}{"name": "Duhok"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Duhok", city.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale version is a conflict", func(t *testing.T) {
+		mockDB, mock := newMockDB(t)
+		repo := repositories.NewCitiesRepository(mockDB)
+
+		mock.ExpectQuery(update).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(exists).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		_, err := repo.CompareAndUpdate(context.Background(), 3, version, map[string]interface{}{"name": "Duhok"})
+
+		assert.ErrorIs(t, err, repositories.ErrVersionConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing row is not found", func(t *testing.T) {
+		mockDB, mock := newMockDB(t)
+		repo := repositories.NewCitiesRepository(mockDB)
+
+		mock.ExpectQuery(update).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(exists).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		_, err := repo.CompareAndUpdate(context.Background(), 3, version, map[string]interface{}{"name": "Duhok"})
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("tables without updated_at are not versioned", func(t *testing.T) {
+		mockDB, _ := newMockDB(t)
+		repo := repositories.NewFacilityOperatingHoursRepository(mockDB)
+
+		_, err := repo.CompareAndUpdate(context.Background(), 3, version, map[string]interface{}{"is_closed": true})
+
+		assert.ErrorIs(t, err, repositories.ErrNotVersioned)
+	})
+}
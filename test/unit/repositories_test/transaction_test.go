@@ -0,0 +1,191 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"server/internal/models"
+	"server/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithinTransactionBindsRepositoriesToOneTransaction(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(1, "Najaf"))
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(2, "Karbala"))
+	mock.ExpectCommit()
+
+	err := transactor.WithinTransaction(context.Background(), repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		if _, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"}); err != nil {
+			return err
+		}
+		_, err := uow.Cities().CreateMany(ctx, []models.City{{Name: "Karbala"}})
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTransactionRollsBackNestedSavepointOnly(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+	errInner := errors.New("inner failed")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(1, "Najaf"))
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO cities").WillReturnError(errors.New("duplicate key"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := transactor.WithinTransaction(context.Background(), repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		if _, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"}); err != nil {
+			return err
+		}
+
+		err := transactor.WithinTransaction(ctx, repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+			if _, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"}); err != nil {
+				return errInner
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errInner)
+
+		return transactor.WithinTransaction(ctx, repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+			return nil
+		})
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTransactionRestoresOuterActorAfterNestedCall(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+	outerID, innerID := int64(1), int64(2)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").WithArgs("1", "req-outer", "").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("set_config").WithArgs("2", "req-inner", "nested").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(1, "Najaf"))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("set_config").WithArgs("1", "req-outer", "").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(2, "Karbala"))
+	mock.ExpectCommit()
+
+	outer := repositories.TxOptions{Actor: &repositories.Actor{UserID: &outerID, RequestID: "req-outer"}}
+	err := transactor.WithinTransaction(context.Background(), outer, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		inner := repositories.TxOptions{Actor: &repositories.Actor{UserID: &innerID, RequestID: "req-inner", Reason: "nested"}}
+		err := transactor.WithinTransaction(ctx, inner, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+			_, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// Attributed to the outer actor again
+		_, err = uow.Cities().Create(ctx, &models.City{Name: "Karbala"})
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTransactionRetriesSerializationFailures(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(1, "Najaf"))
+	mock.ExpectCommit()
+
+	attempts := 0
+	err := transactor.WithinTransaction(context.Background(), repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		attempts++
+		_, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"})
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTransactionDoesNotRetryOtherErrors(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	attempts := 0
+	err := transactor.WithinTransaction(context.Background(), repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		attempts++
+		_, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"})
+		return err
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryMetricsAreTrackedInsideTransactions(t *testing.T) {
+	mockDB, mock := newMockDB(t)
+	transactor := repositories.NewTransactor(mockDB)
+	before := queryTotal(t, "cities", "INSERT", "success")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO cities").WillReturnRows(cityRow(1, "Najaf"))
+	mock.ExpectCommit()
+
+	err := transactor.WithinTransaction(context.Background(), repositories.TxOptions{}, func(ctx context.Context, uow *repositories.UnitOfWork) error {
+		_, err := uow.Cities().Create(ctx, &models.City{Name: "Najaf"})
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, before+1, queryTotal(t, "cities", "INSERT", "success"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// queryTotal reads database_query_total for the given labels from the default registry.
+func queryTotal(t *testing.T, table, operation, status string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "database_query_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["table"] == table && labels["operation"] == operation && labels["status"] == status {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
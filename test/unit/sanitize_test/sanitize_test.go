@@ -0,0 +1,63 @@
+package sanitize_test
+
+import (
+	"testing"
+
+	"server/pkg/sanitize"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	Street string
+	Notes  string `sanitize:"ugc"`
+}
+
+type profile struct {
+	Name      string
+	Bio       *string `sanitize:"ugc"`
+	Password  string  `sanitize:"-"`
+	Tags      []string
+	Addresses []address
+	Labels    map[string]string
+	Extra     any
+	secret    string
+}
+
+func TestStructSanitizesNestedFieldsByPolicy(t *testing.T) {
+	bio := `<a href="https://example.com" onclick="steal()">site</a><script>alert(1)</script>`
+	p := profile{
+		Name:      `<img src=x onerror=alert(1)>Tom & Jerry`,
+		Bio:       &bio,
+		Password:  `<p>s3cret</p>`,
+		Tags:      []string{`<b>x</b>`},
+		Addresses: []address{{Street: `<i>Main</i> St`, Notes: `<em>gate</em><script>x</script>`}},
+		Labels:    map[string]string{"k": `<u>v</u>`},
+		Extra:     `<script>x</script>y`,
+		secret:    `<b>kept</b>`,
+	}
+
+	sanitize.Struct(&p)
+
+	assert.Equal(t, "Tom & Jerry", p.Name)
+	assert.Equal(t, `<a href="https://example.com" rel="nofollow">site</a>`, *p.Bio)
+	assert.Equal(t, `<p>s3cret</p>`, p.Password)
+	assert.Equal(t, []string{"x"}, p.Tags)
+	assert.Equal(t, address{Street: "Main St", Notes: "<em>gate</em>"}, p.Addresses[0])
+	assert.Equal(t, map[string]string{"k": "v"}, p.Labels)
+	assert.Equal(t, "y", p.Extra)
+	assert.Equal(t, `<b>kept</b>`, p.secret)
+}
+
+func TestStrictKeepsEncodedMarkupEscaped(t *testing.T) {
+	// Decoding would turn the text into a tag, so the entities stay
+	assert.Equal(t, "&lt;script&gt;", sanitize.Strict("&lt;script&gt;"))
+	assert.Equal(t, "a < b", sanitize.Strict("a < b"))
+}
+
+func TestStructPanicsOnUnknownPolicy(t *testing.T) {
+	type bad struct {
+		Field string `sanitize:"loose"`
+	}
+	assert.Panics(t, func() { sanitize.Struct(&bad{}) })
+}
@@ -0,0 +1,80 @@
+package schemacheck_test
+
+import (
+	"testing"
+	"time"
+
+	"server/internal/models"
+	"server/internal/schemacheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	models.BaseModel
+	Name      string `db:"name"`
+	Notes     string `db:"notes"`
+	Count     *int   `db:"count"`
+	Color     string `db:"color"`
+	Untagged  string
+	DeletedAt *time.Time     `db:"deleted_at"`
+	Settings  models.JSONMap `db:"settings"`
+}
+
+func (widget) TableName() string { return "widgets" }
+
+func widgetColumns() []schemacheck.Column {
+	return []schemacheck.Column{
+		{Table: "widgets", Name: "id", DataType: "bigint"},
+		{Table: "widgets", Name: "created_at", DataType: "timestamp with time zone"},
+		{Table: "widgets", Name: "updated_at", DataType: "timestamp with time zone"},
+		{Table: "widgets", Name: "name", DataType: "character varying"},
+		{Table: "widgets", Name: "notes", DataType: "text", Nullable: true},
+		{Table: "widgets", Name: "count", DataType: "integer"},
+		{Table: "widgets", Name: "color", DataType: "integer"},
+		{Table: "widgets", Name: "settings", DataType: "jsonb", Nullable: true},
+		{Table: "widgets", Name: "legacy", DataType: "text", Nullable: true},
+	}
+}
+
+func kinds(drifts []schemacheck.Drift) map[string]schemacheck.Kind {
+	found := map[string]schemacheck.Kind{}
+	for _, drift := range drifts {
+		found[drift.Column] = drift.Kind
+	}
+	return found
+}
+
+func TestCompareReportsEveryKindOfDrift(t *testing.T) {
+	drifts := schemacheck.Compare(widgetColumns(), widget{})
+
+	assert.Equal(t, map[string]schemacheck.Kind{
+		"notes":      schemacheck.NullableMismatch,
+		"count":      schemacheck.NotNullMismatch,
+		"color":      schemacheck.TypeMismatch,
+		"untagged":   schemacheck.MissingTag,
+		"deleted_at": schemacheck.MissingColumn,
+		"legacy":     schemacheck.ExtraColumn,
+	}, kinds(drifts))
+}
+
+func TestCompareReportsMissingTable(t *testing.T) {
+	drifts := schemacheck.Compare(nil, widget{})
+
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, schemacheck.MissingTable, drifts[0].Kind)
+}
+
+func TestCompareAcceptsMatchingModel(t *testing.T) {
+	columns := []schemacheck.Column{
+		{Table: "cities", Name: "id", DataType: "bigint"},
+		{Table: "cities", Name: "created_at", DataType: "timestamp with time zone"},
+		{Table: "cities", Name: "updated_at", DataType: "timestamp with time zone"},
+		{Table: "cities", Name: "name", DataType: "character varying"},
+		{Table: "cities", Name: "population", DataType: "integer", Nullable: true},
+		{Table: "cities", Name: "image_url", DataType: "character varying", Nullable: true},
+		{Table: "cities", Name: "timezone", DataType: "character varying", Nullable: true},
+	}
+
+	assert.Empty(t, schemacheck.Compare(columns, models.City{}))
+}
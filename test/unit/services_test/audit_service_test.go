@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"server/internal/models"
+	"server/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshot decodes a row snapshot the way it is read from the audit_log JSONB columns.
+func snapshot(t *testing.T, data string) models.JSONMap {
+	if data == "" {
+		return nil
+	}
+	var m models.JSONMap
+	require.NoError(t, m.Scan(data))
+	return m
+}
+
+func TestDiffAuditData(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []models.FieldChange
+	}{
+		{
+			name: "insert reports every field",
+			new:  `{"id":1,"name":"Al-Kindi"}`,
+			want: []models.FieldChange{
+				{Field: "id", New: json.Number("1")},
+				{Field: "name", New: "Al-Kindi"},
+			},
+		},
+		{
+			name: "delete reports every field",
+			old:  `{"id":1,"name":"Al-Kindi"}`,
+			want: []models.FieldChange{
+				{Field: "id", Old: json.Number("1")},
+				{Field: "name", Old: "Al-Kindi"},
+			},
+		},
+		{
+			name: "update reports changed, added and removed fields sorted by name",
+			old:  `{"id":1,"name":"Al-Kindi","phone":"0770","rating":4.5}`,
+			new:  `{"id":1,"name":"Al-Kindi Teaching","rating":4.5,"website":"kindi.iq"}`,
+			want: []models.FieldChange{
+				{Field: "name", Old: "Al-Kindi", New: "Al-Kindi Teaching"},
+				{Field: "phone", Old: "0770"},
+				{Field: "website", New: "kindi.iq"},
+			},
+		},
+		{
+			name: "nested JSON is compared by value",
+			old:  `{"meta_data":{"beds":{"icu":10,"general":[1,2]}},"tags":["a"]}`,
+			new:  `{"meta_data":{"beds":{"icu":12,"general":[1,2]}},"tags":["a"]}`,
+			want: []models.FieldChange{{
+				Field: "meta_data",
+				Old:   map[string]any{"beds": map[string]any{"icu": json.Number("10"), "general": []any{json.Number("1"), json.Number("2")}}},
+				New:   map[string]any{"beds": map[string]any{"icu": json.Number("12"), "general": []any{json.Number("1"), json.Number("2")}}},
+			}},
+		},
+		{
+			name: "large IDs that collide as float64 still differ",
+			old:  `{"doctor_id":1234567890123456789}`,
+			new:  `{"doctor_id":1234567890123456790}`,
+			want: []models.FieldChange{
+				{Field: "doctor_id", Old: json.Number("1234567890123456789"), New: json.Number("1234567890123456790")},
+			},
+		},
+		{
+			name: "null is a value",
+			old:  `{"phone":null}`,
+			new:  `{"phone":"0770"}`,
+			want: []models.FieldChange{{Field: "phone", New: "0770"}},
+		},
+		{
+			name: "identical snapshots have no changes",
+			old:  `{"id":1,"name":"Al-Kindi"}`,
+			new:  `{"id":1,"name":"Al-Kindi"}`,
+			want: []models.FieldChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, services.DiffAuditData(snapshot(t, tt.old), snapshot(t, tt.new)))
+		})
+	}
+}
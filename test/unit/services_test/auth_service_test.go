@@ -0,0 +1,58 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server/internal/repositories"
+	"server/internal/services"
+	"server/internal/validators"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newAuthService(t *testing.T) (*services.AuthService, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	repo := repositories.NewAuthRepository(sqlx.NewDb(mockDB, "postgres"))
+	return services.NewAuthService(repo, []byte("secret"), time.Hour), mock
+}
+
+func userRow(t *testing.T, password string) *sqlmock.Rows {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return sqlmock.NewRows([]string{"id", "name", "email", "phone_number", "password", "role"}).
+		AddRow(3, "Sara", "sara@example.com", "07701234567", string(hash), "user")
+}
+
+func TestLoginUserByPhoneNumber(t *testing.T) {
+	service, mock := newAuthService(t)
+	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("07701234567").WillReturnRows(userRow(t, "password123"))
+
+	user, err := service.LoginUser(context.Background(), &validators.TLoginRequest{
+		PhoneNumber: "07701234567", Password: "password123",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "sara@example.com", user.Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginUserPrefersEmail(t *testing.T) {
+	service, mock := newAuthService(t)
+	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("sara@example.com").WillReturnRows(userRow(t, "password123"))
+
+	_, err := service.LoginUser(context.Background(), &validators.TLoginRequest{
+		Email: "sara@example.com", PhoneNumber: "07701234567", Password: "wrong-password",
+	})
+
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
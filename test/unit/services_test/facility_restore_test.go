@@ -0,0 +1,39 @@
+package services_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"server/internal/repositories"
+	"server/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreFacilityReadsSnapshotAfterLockingFacility(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	db := sqlx.NewDb(sqlDB, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM "facilities" WHERE id = $1 FOR UPDATE`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery("FROM audit_log").
+		WithArgs("facilities", "id", "7", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	service := services.NewAuditService(repositories.NewAuditLogRepository(db), repositories.NewTransactor(db))
+	_, err = service.RestoreFacility(context.Background(), repositories.Actor{}, 7, time.Now())
+
+	assert.ErrorIs(t, err, services.ErrFacilitySnapshotNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,143 @@
+package sqlinstrument_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"server/pkg/sqlinstrument"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSanitizeReplacesLiterals(t *testing.T) {
+	tests := map[string]string{
+		"SELECT * FROM facilities WHERE id = $1":                   "SELECT * FROM facilities WHERE id = $1",
+		"SELECT *\n  FROM users WHERE email = 'ali@example.com'":   "SELECT * FROM users WHERE email = ?",
+		"UPDATE t SET note = 'it''s', n = 42, x2 = 3.5 WHERE id=7": "UPDATE t SET note = ?, n = ?, x2 = ? WHERE id=?",
+		"SELECT updated_at + interval '1 microsecond' FROM t":      "SELECT updated_at + interval ? FROM t",
+	}
+	for statement, want := range tests {
+		assert.Equal(t, want, sqlinstrument.Sanitize(statement), statement)
+	}
+}
+
+func TestDescribeFindsOperationAndTable(t *testing.T) {
+	tests := []struct{ statement, operation, table string }{
+		{"SELECT * FROM facilities WHERE id = $1", "SELECT", "facilities"},
+		{"insert into public.audit_log (a) values ($1)", "INSERT", "audit_log"},
+		{`UPDATE "cities" SET name = $1`, "UPDATE", "cities"},
+		{"INSERT INTO reviews(facility_id, rating) VALUES ($1, $2)", "INSERT", "reviews"},
+		{"DELETE FROM ONLY reviews WHERE id = $1", "DELETE", "reviews"},
+		{"SELECT count(*) FROM (SELECT id FROM doctors) d", "SELECT", "doctors"},
+		{"SAVEPOINT sp_1", "SAVEPOINT", ""},
+	}
+	for _, tt := range tests {
+		operation, table := sqlinstrument.Describe(tt.statement)
+		assert.Equal(t, tt.operation, operation, tt.statement)
+		assert.Equal(t, tt.table, table, tt.statement)
+	}
+}
+
+func TestOpenTracesEveryQuery(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(sdktrace.NewTracerProvider()) })
+
+	_, mock, err := sqlmock.NewWithDSN("sqlinstrument_test")
+	require.NoError(t, err)
+	mock.ExpectQuery("SELECT name FROM cities").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Baghdad"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE cities").WillReturnError(errors.New("deadlock"))
+	mock.ExpectRollback()
+
+	sqlDB, err := sqlinstrument.Open("sqlmock", "sqlinstrument_test")
+	require.NoError(t, err)
+	db := sqlx.NewDb(sqlDB, "postgres")
+	defer db.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "CityService.GetCityByID")
+	var names []string
+	require.NoError(t, db.SelectContext(ctx, &names, "SELECT name FROM cities WHERE name <> 'Basra'"))
+	tx, err := db.BeginTxx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "UPDATE cities SET name = $1", "Erbil")
+	require.Error(t, err)
+	require.NoError(t, tx.Rollback())
+	parent.End()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	selectSpan, updateSpan := spans[0], spans[1]
+
+	assert.Equal(t, "SELECT cities", selectSpan.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), selectSpan.Parent().SpanID())
+	assert.Contains(t, selectSpan.Attributes(), attribute.String("db.query.text", "SELECT name FROM cities WHERE name <> ?"))
+	assert.Contains(t, selectSpan.Attributes(), attribute.String("db.collection.name", "cities"))
+	assert.Contains(t, selectSpan.Attributes(), attribute.String("db.system", "sqlmock"))
+
+	assert.Equal(t, "UPDATE cities", updateSpan.Name())
+	assert.Equal(t, codes.Error, updateSpan.Status().Code)
+}
+
+func TestOpenMeasuresQueriesUntilRowsAreClosed(t *testing.T) {
+	_, mock, err := sqlmock.NewWithDSN("sqlinstrument_metrics_test")
+	require.NoError(t, err)
+	mock.ExpectQuery("SELECT name FROM districts").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Karkh"))
+	mock.ExpectExec("DELETE FROM districts").WillReturnError(errors.New("deadlock"))
+
+	sqlDB, err := sqlinstrument.Open("sqlmock", "sqlinstrument_metrics_test")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	succeeded := metricValue(t, "database_query_total", "districts", "SELECT", "success")
+	failed := metricValue(t, "database_query_total", "districts", "DELETE", "failure")
+
+	rows, err := sqlDB.QueryContext(context.Background(), "SELECT name FROM districts")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, metricValue(t, "database_in_flight_queries", "districts", "SELECT", ""), "in flight while rows are read")
+	require.NoError(t, rows.Close())
+	assert.Equal(t, 0.0, metricValue(t, "database_in_flight_queries", "districts", "SELECT", ""))
+	assert.Equal(t, succeeded+1, metricValue(t, "database_query_total", "districts", "SELECT", "success"))
+
+	_, err = sqlDB.ExecContext(context.Background(), "DELETE FROM districts WHERE id = $1", 1)
+	require.Error(t, err)
+	assert.Equal(t, failed+1, metricValue(t, "database_query_total", "districts", "DELETE", "failure"))
+	assert.Equal(t, 0.0, metricValue(t, "database_in_flight_queries", "districts", "DELETE", ""))
+}
+
+// metricValue reads a counter or gauge of the default registry by its table, operation and status
+// labels; an empty status matches metrics without one.
+func metricValue(t *testing.T, name, table, operation, status string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["table"] != table || labels["operation"] != operation || labels["status"] != status {
+				continue
+			}
+			if metric.GetGauge() != nil {
+				return metric.GetGauge().GetValue()
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
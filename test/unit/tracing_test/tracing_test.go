@@ -0,0 +1,68 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"server/pkg/logger"
+	"server/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent   = "00-" + callerTraceID + "-00f067aa0ba902b7-01"
+)
+
+func TestMiddlewareContinuesCallerTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger.SetLogger(zap.New(core))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+		logger.SetLogger(zap.NewNop())
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tracing.Middleware("test", "/healthz"))
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/cities/:id", func(c *gin.Context) {
+		ctx, span := tracing.Start(c.Request.Context(), "CityService.GetCityByID")
+		logger.FromContext(ctx).Info("City loaded")
+		tracing.End(span, context.Canceled)
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/cities/1", "/healthz"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("traceparent", traceparent)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2, "skipped paths are not traced")
+	service, server := spans[0], spans[1]
+	assert.Equal(t, "/api/cities/:id", server.Name())
+	assert.Equal(t, callerTraceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+	assert.Len(t, service.Events(), 1, "the error is recorded")
+
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, callerTraceID, fields["trace_id"])
+	assert.Equal(t, service.SpanContext().SpanID().String(), fields["span_id"])
+}
@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"server/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnowflakeIDsAreUniqueAndDecodable(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	node, err := utils.NewSnowflakeNode(3, 17, func() time.Time { return now })
+	require.NoError(t, err)
+
+	first, err := node.NextID()
+	require.NoError(t, err)
+	second, err := node.NextID()
+	require.NoError(t, err)
+
+	assert.Greater(t, second, first)
+	assert.Equal(t, utils.SnowflakeID{ID: second, Timestamp: now, DataCenterID: 3, MachineID: 17, Sequence: 1},
+		utils.DecodeSnowflakeID(second))
+}
+
+func TestSnowflakeNodeRejectsOutOfRangeIDs(t *testing.T) {
+	_, err := utils.NewSnowflakeNode(utils.MaxDataCenterID+1, 0, nil)
+	assert.ErrorIs(t, err, utils.ErrInvalidNodeID)
+
+	_, err = utils.NewSnowflakeNode(0, utils.MaxMachineID+1, nil)
+	assert.ErrorIs(t, err, utils.ErrInvalidNodeID)
+
+	_, err = utils.NewSnowflakeNode(-1, 0, nil)
+	assert.ErrorIs(t, err, utils.ErrInvalidNodeID)
+}
+
+func TestSnowflakeNodeDetectsClockRollback(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	node, err := utils.NewSnowflakeNode(0, 0, func() time.Time { return now })
+	require.NoError(t, err)
+
+	_, err = node.NextID()
+	require.NoError(t, err)
+
+	now = now.Add(-time.Second)
+	_, err = node.NextID()
+	assert.ErrorIs(t, err, utils.ErrClockMovedBackwards)
+}
+
+func TestMachineIDFromHostname(t *testing.T) {
+	id, err := utils.MachineIDFromHostname("mydoctor-api-7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+
+	_, err = utils.MachineIDFromHostname("laptop")
+	assert.Error(t, err)
+
+	_, err = utils.MachineIDFromHostname("mydoctor-api-32")
+	assert.ErrorIs(t, err, utils.ErrInvalidNodeID)
+}
@@ -0,0 +1,113 @@
+package validators_test
+
+import (
+	"errors"
+	"testing"
+
+	"server/internal/validators"
+	"server/pkg/apperrors"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(v string) *string { return &v }
+
+func validFacility() validators.CreateFacilityRequest {
+	return validators.CreateFacilityRequest{
+		FacilityRequest: validators.FacilityRequest{
+			Name: "Al-Kindi Hospital", Description: "Teaching hospital", Type: "Teaching Hospital", CityID: 1,
+		},
+		Location:    "Baghdad",
+		Coordinates: strPtr("33.3152,44.3661"),
+		Phone:       strPtr("07701234567"),
+		OperatingHours: []validators.OperatingHoursRequest{
+			{DayOfWeek: intPtr(0), StartTime: "08:00", EndTime: "16:30"},
+			{DayOfWeek: intPtr(5), IsClosed: true},
+		},
+	}
+}
+
+// fieldErrors validates req and returns its field errors in the given language.
+func fieldErrors(t *testing.T, req any, acceptLanguage string) map[string]string {
+	err := binding.Validator.ValidateStruct(req)
+	require.Error(t, err)
+
+	var appErr *apperrors.Error
+	require.True(t, errors.As(validators.BindError(err, acceptLanguage), &appErr))
+	assert.Equal(t, apperrors.KindValidation, appErr.Kind)
+
+	fields := map[string]string{}
+	for _, field := range appErr.Fields {
+		fields[field.Field] = field.Message
+	}
+	return fields
+}
+
+func TestValidFacilityRequestPasses(t *testing.T) {
+	req := validFacility()
+	assert.NoError(t, binding.Validator.ValidateStruct(&req))
+}
+
+func TestCustomValidatorsRejectInvalidValues(t *testing.T) {
+	req := validFacility()
+	req.Type = "Spa"
+	req.Coordinates = strPtr("133.3,44.3")
+	req.Phone = strPtr("07101234567")
+	req.OperatingHours[0].EndTime = "08:00"
+	req.OperatingHours[1] = validators.OperatingHoursRequest{DayOfWeek: intPtr(7), StartTime: "25:00"}
+
+	fields := fieldErrors(t, &req, "")
+
+	assert.Contains(t, fields["type"], "must be one of: Public Hospital")
+	assert.Equal(t, "coordinates must be a \"latitude,longitude\" pair within valid ranges", fields["coordinates"])
+	assert.Contains(t, fields["phone"], "Iraqi mobile number")
+	assert.Equal(t, "end_time must differ from start_time", fields["operating_hours[0].end_time"])
+	assert.Contains(t, fields, "operating_hours[1].day_of_week")
+	assert.Equal(t, "start_time must be a time of day in HH:MM format", fields["operating_hours[1].start_time"])
+	assert.Contains(t, fields, "operating_hours[1].end_time")
+}
+
+func TestOperatingHoursMayRunPastMidnight(t *testing.T) {
+	req := validFacility()
+	req.OperatingHours[0].StartTime = "22:00"
+	req.OperatingHours[0].EndTime = "06:00"
+
+	assert.NoError(t, binding.Validator.ValidateStruct(&req))
+}
+
+func TestIANATimeZone(t *testing.T) {
+	validate := binding.Validator.Engine().(*validator.Validate)
+
+	assert.NoError(t, validate.Var("Asia/Baghdad", "iana_timezone"))
+	assert.Error(t, validate.Var("Local", "iana_timezone"))
+	assert.Error(t, validate.Var("Asia/Mosul", "iana_timezone"))
+}
+
+func TestFieldErrorsAreTranslated(t *testing.T) {
+	req := validators.TLoginRequest{PhoneNumber: "12345"}
+
+	fields := fieldErrors(t, &req, "fr-FR, ar-IQ;q=0.9, en;q=0.8")
+
+	assert.Equal(t, "حقل password مطلوب", fields["password"])
+	assert.Contains(t, fields["phoneNumber"], "رقم هاتف محمول عراقي")
+}
+
+func TestLoginRequiresEmailOrPhone(t *testing.T) {
+	fields := fieldErrors(t, &validators.TLoginRequest{Password: "secret"}, "en")
+	assert.Contains(t, fields, "email")
+	assert.Contains(t, fields, "phoneNumber")
+
+	assert.NoError(t, binding.Validator.ValidateStruct(&validators.TLoginRequest{PhoneNumber: "+9647801234567", Password: "secret"}))
+}
+
+func TestMalformedBodyIsAValidationError(t *testing.T) {
+	err := validators.BindError(errors.New("unexpected EOF"), "ar")
+
+	assert.Equal(t, apperrors.KindValidation, apperrors.KindOf(err))
+	assert.Contains(t, err.Error(), "نص الطلب غير صالح")
+}